@@ -14,6 +14,21 @@ type Camera struct {
 	Fov
 	LookAt   Position
 	DrawType int
+	Stats    CullStats
+
+	ShadingMode  int
+	PhongProgram uint32
+	GammaCorrect bool
+
+	Bookmarks        []Bookmark
+	SelectedBookmark int
+}
+
+// Bookmark is a saved camera view.
+type Bookmark struct {
+	Rotation
+	Position
+	Fov
 }
 
 type Rotation struct {
@@ -84,6 +99,34 @@ func (camera *Camera) Reset() {
 	camera.Z = 0
 }
 
+func (camera *Camera) CurrentView() Bookmark {
+	return Bookmark{Rotation: camera.Rotation, Position: camera.Position, Fov: camera.Fov}
+}
+
+func (camera *Camera) GoTo(b Bookmark) {
+	camera.Rotation = b.Rotation
+	camera.Position = b.Position
+	camera.Fov = b.Fov
+	camera.UpdateCamera()
+}
+
+func (camera *Camera) AddBookmark() {
+	camera.Bookmarks = append(camera.Bookmarks, camera.CurrentView())
+	camera.SelectedBookmark = len(camera.Bookmarks) - 1
+	log.Println("Added camera bookmark", camera.SelectedBookmark)
+}
+
+func (camera *Camera) NextBookmark() {
+	if len(camera.Bookmarks) == 0 {
+		log.Println("No camera bookmarks")
+		return
+	}
+
+	camera.SelectedBookmark = (camera.SelectedBookmark + 1) % len(camera.Bookmarks)
+	camera.GoTo(camera.Bookmarks[camera.SelectedBookmark])
+	log.Println("Camera bookmark --->", camera.SelectedBookmark)
+}
+
 func (camera *Camera) ChangeDrawType() {
 	if camera.DrawType == 0 {
 		camera.DrawType = 1
@@ -2,6 +2,7 @@ package Camera
 
 import (
 	"math"
+	"sort"
 
 	"github.com/go-gl/gl/v4.1-compatibility/gl"
 	"github.com/go-gl/mathgl/mgl32"
@@ -9,33 +10,105 @@ import (
 	"github.com/kanister10l/GoCamera/World"
 )
 
-func (camera *Camera) DrawSphere(spherePoints []SpherePoint, ratio float32, sp *SphereWorld) {
-	yCanvas := float32(20)
-	xCanvas := ratio * 20
+// DrawSphere lights the sphere for a viewer at the camera and projects it
+// through the camera like the world. Polygons are drawn back to front. They
+// are made again only when the sphere world, the mesh or the camera view
+// changed since the previous frame.
+func (camera *Camera) DrawSphere(mesh *SphereMesh, sp *SphereWorld) {
+	key := sphereCacheKey{mesh: mesh, view: camera.CurrentView(), gamma: camera.GammaCorrect}
+	if sp.dirty || sp.cacheKey != key {
+		drawer := []float32{}
+		colors := []float32{}
+		for _, p := range camera.SpherePolygons(mesh, sp) {
+			if p.Visible {
+				drawer = append(drawer, p.Drawer...)
+				colors = append(colors, p.Color...)
+			}
+		}
 
-	var poly []SpherePolygon
+		sp.vertices.Fill(drawer, colors)
+		sp.cacheKey = key
+		sp.dirty = false
+	}
 
-	if ratio < 1 {
-		xCanvas = 20
-		yCanvas = 20 / ratio
+	sp.vertices.Draw()
+}
+
+// SpherePolygons lights and projects the sphere with the selected shading
+// model, or with every model side by side, sorted back to front.
+func (camera *Camera) SpherePolygons(mesh *SphereMesh, sp *SphereWorld) []SpherePolygon {
+	polygons := []SpherePolygon{}
+	spherePoints := mesh.Points
+
+	models := []ShadingModel{ShadingModels[sp.Model]}
+	if sp.Compare {
+		models = ShadingModels
 	}
 
-	if sp.Prepared {
-		spherePoints = CalculateMaterialIntensity(spherePoints, sp.X, sp.Y, sp.Z, 0, 0, 0, sp.Materials[sp.SelectedMaterial])
-		poly = PolygonyfyMaterial(spherePoints, xCanvas, yCanvas)
-	} else {
-		spherePoints = CalculateLightIntensity(spherePoints, sp.X, sp.Y, sp.Z, 0, 0, 0, sp.Ka, sp.Kd, sp.Ks, sp.N)
-		poly = Polygonyfy(spherePoints, xCanvas, yCanvas, sp.Hue)
+	lights := camera.LightingSphereLights(sp.Lights)
+	for k, model := range models {
+		var poly []SpherePolygon
+		if sp.Prepared {
+			mat := camera.LightingMaterial(sp.Materials[sp.SelectedMaterial])
+			spherePoints = CalculateMaterialIntensity(spherePoints, lights, model, camera.X, camera.Y, camera.Z, mat)
+			poly = PolygonyfyMaterial(mesh, camera.ProjectSphere(spherePoints), 1, 1, sp.Tone, camera.GammaCorrect)
+		} else {
+			spherePoints = CalculateLightIntensity(spherePoints, lights, model, camera.X, camera.Y, camera.Z, sp.Ka, sp.Kd, sp.Ks, sp.N)
+			poly = Polygonyfy(mesh, camera.ProjectSphere(spherePoints), 1, 1, sp.Hue, sp.Tone, camera.GammaCorrect)
+		}
+
+		if len(models) > 1 {
+			PlacePolygons(poly, 1/float32(len(models)), -1+float32(2*k+1)/float32(len(models)), 0)
+		}
+
+		sort.SliceStable(poly, func(i, j int) bool {
+			return poly[i].Dist > poly[j].Dist
+		})
+		polygons = append(polygons, poly...)
 	}
 
+	return polygons
+}
+
+// ProjectSphere returns a copy of the sphere points with X and Y in screen
+// coordinates, the distance to the camera and whether the camera sees them.
+func (camera *Camera) ProjectSphere(points []SpherePoint) []SpherePoint {
+	projected := make([]SpherePoint, len(points))
+	parallelFor(len(points), func(start, end int) {
+		for k := start; k < end; k++ {
+			point := points[k]
+			visible, angleX, angleY := camera.CheckVisibility(World.Point{X: point.X, Y: point.Y, Z: point.Z})
+			projected[k] = point
+			projected[k].X, projected[k].Y = Helpers.NormalizePosition(angleX, angleY, camera.HorizontalFov/2, camera.VerticalFov/2)
+			projected[k].Dist = mgl32.Vec3{point.X - camera.X, point.Y - camera.Y, point.Z - camera.Z}.Len()
+			projected[k].Visible = visible
+		}
+	})
+
+	return projected
+}
+
+// PlacePolygons scales sphere polygons around the middle of the screen and
+// moves them to x, y in screen coordinates.
+func PlacePolygons(poly []SpherePolygon, scale, x, y float32) {
 	for _, p := range poly {
-		gl.BindVertexArray(Helpers.MakeVao(p.Drawer, p.Color, false))
-		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(p.Drawer)/3))
+		for i := 0; i+2 < len(p.Drawer); i += 3 {
+			p.Drawer[i] = p.Drawer[i]*scale + x
+			p.Drawer[i+1] = p.Drawer[i+1]*scale + y
+		}
 	}
 }
 
-func (camera *Camera) DrawWorld(world *World.World) {
-	for _, entity := range world.Entities {
+func (camera *Camera) DrawWorld(world *World.World, materials WorldMaterials) {
+	drawn := camera.CullEntities(world)
+
+	for k, entity := range world.Entities {
+		if !drawn[k] {
+			continue
+		}
+
+		color := EntityColor(&world.Entities[k], materials)
+
 		for _, line := range entity.Lines {
 			p1Visible, p1AngleX, p1AngleY := camera.CheckVisibility(entity.Points[line.P1])
 			p2Visible, p2AngleX, p2AngleY := camera.CheckVisibility(entity.Points[line.P2])
@@ -49,21 +122,33 @@ func (camera *Camera) DrawWorld(world *World.World) {
 					x2, y2, 0,
 				}
 
-				gl.BindVertexArray(Helpers.MakeVao(drawLine, []float32{}, true))
+				gl.BindVertexArray(Helpers.MakeVao(drawLine, color, false))
 				gl.DrawArrays(gl.TRIANGLES, 0, int32(len(drawLine)/3))
 			}
 		}
 	}
 }
 
-func (camera *Camera) DrawFullWorld(world *World.World) {
+func (camera *Camera) DrawFullWorld(world *World.World, materials WorldMaterials, model ShadingModel) {
 	figures := []BSPFigure{}
 
+	drawn := camera.CullEntities(world)
+
 	polygons := []Polygon{}
 	for k1, entity := range world.Entities {
 		newFigure := BSPFigure{}
 		newFigure.Init()
 		figures = append(figures, newFigure)
+		if !drawn[k1] {
+			continue
+		}
+
+		mat := camera.LightingMaterial(EntityMaterial(&world.Entities[k1], materials))
+		if entity.Type != "square" || camera.ShadingMode != ShadingFlat {
+			polygons = append(polygons, camera.FacePolygons(&world.Entities[k1], &mat, world.Lights, model)...)
+			continue
+		}
+
 		for _, line := range entity.Lines {
 			visible := false
 			p1Visible, p1AngleX, p1AngleY := camera.CheckVisibility(entity.Points[line.P1])
@@ -85,6 +170,10 @@ func (camera *Camera) DrawFullWorld(world *World.World) {
 			if figures[k1].Frames[k2].Visible {
 				poly1, poly2, err := figures[k1].Frames[k2].ConvertToPolygons()
 				if err == nil {
+					point, normal := SideSurface(&world.Entities[k1], figures[k1].Frames[k2].Side)
+					color := camera.ShadeSurface(point, normal, mat, world.Lights, model)
+					poly1.Color = VertexColors(color, 3)
+					poly2.Color = VertexColors(color, 3)
 					polygons = append(polygons, poly1, poly2)
 				}
 			}
@@ -93,6 +182,7 @@ func (camera *Camera) DrawFullWorld(world *World.World) {
 
 	polyLen := len(polygons)
 
+	var phongMaterial *MaterialElement
 	for i := 0; i < polyLen; i++ {
 		min := polygons[0].Dist
 		toRender := 0
@@ -103,9 +193,23 @@ func (camera *Camera) DrawFullWorld(world *World.World) {
 			}
 		}
 
-		gl.BindVertexArray(Helpers.MakeVao(polygons[toRender].Drawer, []float32{}, true))
-		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(polygons[toRender].Drawer)/3))
+		polygon := polygons[toRender]
 		polygons = append(polygons[:toRender], polygons[toRender+1:]...)
+		if polygon.Material != nil {
+			if phongMaterial == nil {
+				gl.UseProgram(camera.PhongProgram)
+				camera.SetPhongLights(world.Lights, model)
+			}
+			if polygon.Material != phongMaterial {
+				camera.SetPhongMaterial(polygon.Material)
+				phongMaterial = polygon.Material
+			}
+
+			gl.BindVertexArray(Helpers.MakeVaoAttributes(polygon.Drawer, polygon.Positions, polygon.Normals))
+		} else {
+			gl.BindVertexArray(Helpers.MakeVao(polygon.Drawer, polygon.Color, false))
+		}
+		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(polygon.Drawer)/3))
 	}
 
 	/*tree := BuildTree(polygons)
@@ -113,6 +217,71 @@ func (camera *Camera) DrawFullWorld(world *World.World) {
 	Traverse(tree)*/
 }
 
+// FacePolygons projects and shades the faces of an entity which are at least
+// partially visible. Entities other than squares, and squares in smooth
+// shading modes, are drawn this way in DrawFullWorld. Squares keep sharp
+// edges by using face normals at their corners, other entities use vertex
+// normals. Per-pixel polygons carry the material for the Phong shader and fall
+// back to Gouraud shading when the shader is not available. Gouraud shading
+// interpolates vertex colors already encoded by OutputColor, so with gamma
+// correction the blend across a face is in sRGB rather than linear light.
+func (camera *Camera) FacePolygons(entity *World.Entity, mat *MaterialElement, lights []World.Light, model ShadingModel) []Polygon {
+	polygons := []Polygon{}
+	vertexNormals := entity.VertexNormals()
+	entity.FaceIndex().Query(camera.Frustum(), func(k int) bool {
+		face := entity.Faces[k]
+		drawer := []float32{}
+		visible := false
+		dist := float32(0)
+		for _, p := range []int{face.P1, face.P2, face.P3} {
+			pVisible, pAngleX, pAngleY := camera.CheckVisibility(entity.Points[p])
+			x, y := Helpers.NormalizePosition(pAngleX, pAngleY, camera.HorizontalFov/2, camera.VerticalFov/2)
+			drawer = append(drawer, x, y, 0)
+			dist += mgl32.NewVecNFromData([]float32{entity.Points[p].X - camera.X, entity.Points[p].Y - camera.Y, entity.Points[p].Z - camera.Z}).Vec3().Len() / 3
+			if pVisible {
+				visible = true
+			}
+		}
+
+		if !visible {
+			return true
+		}
+
+		p1 := entity.Points[face.P1]
+		p2 := entity.Points[face.P2]
+		p3 := entity.Points[face.P3]
+		faceNormal := FaceNormal(p1, p2, p3)
+
+		polygon := MakePolygon(drawer)
+		polygon.Dist = dist
+		if camera.ShadingMode == ShadingFlat {
+			center := mgl32.Vec3{(p1.X + p2.X + p3.X) / 3, (p1.Y + p2.Y + p3.Y) / 3, (p1.Z + p2.Z + p3.Z) / 3}
+			polygon.Color = VertexColors(camera.ShadeSurface(center, faceNormal, *mat, lights, model), 3)
+		} else {
+			for _, p := range []int{face.P1, face.P2, face.P3} {
+				position := mgl32.Vec3{entity.Points[p].X, entity.Points[p].Y, entity.Points[p].Z}
+				normal := faceNormal
+				if entity.Type != "square" {
+					normal = mgl32.Vec3{vertexNormals[p].X, vertexNormals[p].Y, vertexNormals[p].Z}
+				}
+
+				if camera.ShadingMode == ShadingPhong && camera.PhongProgram != 0 {
+					polygon.Positions = append(polygon.Positions, position[0], position[1], position[2])
+					polygon.Normals = append(polygon.Normals, normal[0], normal[1], normal[2])
+					polygon.Material = mat
+				} else {
+					polygon.Color = append(polygon.Color, camera.ShadeSurface(position, normal, *mat, lights, model)...)
+				}
+			}
+		}
+		polygons = append(polygons, polygon)
+
+		return true
+	})
+
+	return polygons
+}
+
 func (camera *Camera) CheckVisibility(point World.Point) (bool, float32, float32) {
 	poi := mgl32.NewVecNFromData([]float32{point.X - camera.X, point.Y - camera.Y, point.Z - camera.Z}).Vec3()
 	vNorm := mgl32.NewVecNFromData([]float32{camera.XVector[0], camera.XVector[1], camera.XVector[2]}).Vec3().Normalize()
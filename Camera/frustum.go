@@ -0,0 +1,104 @@
+package Camera
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/kanister10l/GoCamera/Helpers"
+	"github.com/kanister10l/GoCamera/World"
+)
+
+// CullStats counts entities rejected by frustum culling and entities passed on
+// to per-line work during the last drawn frame.
+type CullStats struct {
+	Culled int
+	Drawn  int
+}
+
+// Frustum is the camera view volume given by inward facing normals of its
+// planes. All planes pass through Position, the first one is the near plane.
+type Frustum struct {
+	Position mgl32.Vec3
+	Planes   []mgl32.Vec3
+}
+
+func (camera *Camera) Frustum() Frustum {
+	x := mgl32.Vec3{camera.XVector[0], camera.XVector[1], camera.XVector[2]}.Normalize()
+	y := mgl32.Vec3{camera.YVector[0], camera.YVector[1], camera.YVector[2]}.Normalize()
+	z := mgl32.Vec3{camera.ZVector[0], camera.ZVector[1], camera.ZVector[2]}.Normalize()
+
+	hSin := float32(math.Sin(float64(Helpers.DegToRad(camera.HorizontalFov / 2))))
+	hCos := float32(math.Cos(float64(Helpers.DegToRad(camera.HorizontalFov / 2))))
+	vSin := float32(math.Sin(float64(Helpers.DegToRad(camera.VerticalFov / 2))))
+	vCos := float32(math.Cos(float64(Helpers.DegToRad(camera.VerticalFov / 2))))
+
+	return Frustum{
+		Position: mgl32.Vec3{camera.X, camera.Y, camera.Z},
+		Planes: []mgl32.Vec3{
+			z,
+			z.Mul(hSin).Add(x.Mul(hCos)),
+			z.Mul(hSin).Sub(x.Mul(hCos)),
+			z.Mul(vSin).Add(y.Mul(vCos)),
+			z.Mul(vSin).Sub(y.Mul(vCos)),
+		},
+	}
+}
+
+// OverlapsBox tests the corner of the box furthest along each plane normal. It
+// may report boxes near the frustum edges as overlapping, but never rejects an
+// overlapping one.
+func (f Frustum) OverlapsBox(box World.AABB) bool {
+	for _, n := range f.Planes {
+		p := mgl32.Vec3{box.Min.X, box.Min.Y, box.Min.Z}
+		if n[0] >= 0 {
+			p[0] = box.Max.X
+		}
+		if n[1] >= 0 {
+			p[1] = box.Max.Y
+		}
+		if n[2] >= 0 {
+			p[2] = box.Max.Z
+		}
+		if p.Sub(f.Position).Dot(n) < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f Frustum) OverlapsSphere(sphere World.BoundingSphere) bool {
+	center := mgl32.Vec3{sphere.Center.X, sphere.Center.Y, sphere.Center.Z}.Sub(f.Position)
+	for _, n := range f.Planes {
+		if center.Dot(n) < -sphere.Radius {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CullEntities marks the visible entities whose bounding box and sphere
+// overlap the view frustum and updates Stats.
+func (camera *Camera) CullEntities(world *World.World) []bool {
+	frustum := camera.Frustum()
+	drawn := make([]bool, len(world.Entities))
+	world.QueryEntities(frustum, func(k int) bool {
+		drawn[k] = world.Entities[k].Visible && frustum.OverlapsSphere(world.Entities[k].Sphere)
+		return true
+	})
+
+	camera.Stats = CullStats{}
+	for k := range world.Entities {
+		if !world.Entities[k].Visible {
+			continue
+		}
+		if drawn[k] {
+			camera.Stats.Drawn++
+		} else {
+			camera.Stats.Culled++
+		}
+	}
+
+	return drawn
+}
@@ -0,0 +1,98 @@
+package Camera
+
+import (
+	"log"
+	"math"
+
+	"github.com/kanister10l/GoCamera/World"
+)
+
+// SRGBToLinear decodes an sRGB color component into linear light.
+func SRGBToLinear(c float32) float32 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+
+	return float32(math.Pow(float64((c+0.055)/1.055), 2.4))
+}
+
+// LinearToSRGB encodes a linear color component in the 0-1 range as sRGB.
+func LinearToSRGB(c float32) float32 {
+	if c <= 0.0031308 {
+		return 12.92 * c
+	}
+
+	return float32(1.055*math.Pow(float64(c), 1/2.4) - 0.055)
+}
+
+func linearColor(c Ambient) Ambient {
+	return Ambient{R: SRGBToLinear(c.R), G: SRGBToLinear(c.G), B: SRGBToLinear(c.B)}
+}
+
+// LinearMaterial decodes the sRGB colors of a material into linear light.
+func LinearMaterial(mat MaterialElement) MaterialElement {
+	mat.Ambient = linearColor(mat.Ambient)
+	mat.Diffuse = linearColor(mat.Diffuse)
+	mat.Specular = linearColor(mat.Specular)
+	return mat
+}
+
+// LightingMaterial returns the material to light with, which is linear when
+// gamma correction is on and the sRGB values as given otherwise.
+func (camera *Camera) LightingMaterial(mat MaterialElement) MaterialElement {
+	if camera.GammaCorrect {
+		return LinearMaterial(mat)
+	}
+
+	return mat
+}
+
+func linearLight(light World.Light) World.Light {
+	light.Color = World.Color{R: SRGBToLinear(light.Color.R), G: SRGBToLinear(light.Color.G), B: SRGBToLinear(light.Color.B)}
+	return light
+}
+
+// LightingLights returns the lights to light with, their colors decoded from
+// sRGB like the materials of LightingMaterial when gamma correction is on.
+func (camera *Camera) LightingLights(lights []World.Light) []World.Light {
+	if !camera.GammaCorrect {
+		return lights
+	}
+
+	linear := make([]World.Light, len(lights))
+	for k, light := range lights {
+		linear[k] = linearLight(light)
+	}
+
+	return linear
+}
+
+// LightingSphereLights is LightingLights for the lights of sphere mode.
+func (camera *Camera) LightingSphereLights(lights []SphereLight) []SphereLight {
+	if !camera.GammaCorrect {
+		return lights
+	}
+
+	linear := make([]SphereLight, len(lights))
+	for k, light := range lights {
+		linear[k] = light
+		linear[k].Light = linearLight(light.Light)
+	}
+
+	return linear
+}
+
+// OutputColor encodes a lit RGB color in the 0-1 range for the screen or an
+// image, as sRGB when gamma correction is on.
+func (camera *Camera) OutputColor(color []float32) []float32 {
+	if !camera.GammaCorrect {
+		return color
+	}
+
+	return []float32{LinearToSRGB(color[0]), LinearToSRGB(color[1]), LinearToSRGB(color[2])}
+}
+
+func (camera *Camera) ToggleGammaCorrect() {
+	camera.GammaCorrect = !camera.GammaCorrect
+	log.Println("Gamma correct lighting --->", camera.GammaCorrect)
+}
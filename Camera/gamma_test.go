@@ -0,0 +1,79 @@
+package Camera
+
+import (
+	"testing"
+
+	"github.com/kanister10l/GoCamera/World"
+)
+
+func TestSRGBRoundTrip(t *testing.T) {
+	for k := 0; k <= 255; k++ {
+		c := float32(k) / 255
+		if d := LinearToSRGB(SRGBToLinear(c)) - c; d > 1e-5 || d < -1e-5 {
+			t.Errorf("%v: round trip is off by %v", c, d)
+		}
+	}
+}
+
+func TestSRGBToLinear(t *testing.T) {
+	tests := []struct {
+		in   float32
+		want float32
+	}{
+		{0, 0},
+		{1, 1},
+		{0.04045, 0.04045 / 12.92},
+		{0.5, 0.21404},
+	}
+
+	for _, test := range tests {
+		if d := SRGBToLinear(test.in) - test.want; d > 1e-5 || d < -1e-5 {
+			t.Errorf("SRGBToLinear(%v): got %v, want %v", test.in, SRGBToLinear(test.in), test.want)
+		}
+	}
+}
+
+func TestLinearMaterial(t *testing.T) {
+	mat := MaterialElement{
+		Material:  "test",
+		Shininess: 7,
+		Ambient:   Ambient{R: 0, G: 0.5, B: 1},
+		Diffuse:   Ambient{R: 0.5, G: 0.5, B: 0.5},
+		Specular:  Ambient{R: 1, G: 1, B: 1},
+	}
+
+	linear := LinearMaterial(mat)
+	if linear.Material != "test" || linear.Shininess != 7 {
+		t.Errorf("name or shininess changed: %+v", linear)
+	}
+	if linear.Ambient.R != 0 || linear.Ambient.B != 1 || linear.Specular != mat.Specular {
+		t.Errorf("black and white changed: %+v", linear)
+	}
+	if linear.Diffuse.R != SRGBToLinear(0.5) || linear.Ambient.G != SRGBToLinear(0.5) {
+		t.Errorf("mid gray not decoded: %+v", linear)
+	}
+}
+
+func TestLightingLights(t *testing.T) {
+	lights := []World.Light{{Type: World.LightPoint, Color: World.Color{R: 1, G: 0.5, B: 0}, Intensity: 2}}
+	camera := NewCameraAt(0, 0, 0, 75, 1)
+
+	if got := camera.LightingLights(lights); got[0].Color != lights[0].Color {
+		t.Errorf("without gamma correction got %+v", got[0].Color)
+	}
+
+	camera.GammaCorrect = true
+	got := camera.LightingLights(lights)
+	want := World.Color{R: 1, G: SRGBToLinear(0.5), B: 0}
+	if got[0].Color != want || got[0].Intensity != 2 {
+		t.Errorf("got %+v, want color %+v", got[0], want)
+	}
+	if lights[0].Color.G != 0.5 {
+		t.Error("the given lights were modified")
+	}
+
+	sphereLights := camera.LightingSphereLights([]SphereLight{{Light: lights[0], Enabled: true}})
+	if sphereLights[0].Color != want || !sphereLights[0].Enabled {
+		t.Errorf("sphere light: got %+v", sphereLights[0])
+	}
+}
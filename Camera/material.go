@@ -1,9 +1,15 @@
 package Camera
 
 import (
+	"bytes"
 	"encoding/json"
-	"log"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // Generated by https://quicktype.io
@@ -11,11 +17,13 @@ import (
 type Material []MaterialElement
 
 type MaterialElement struct {
-	Shininess int     `json:"Shininess"`
-	Specular  Ambient `json:"Specular"`
-	Material  string  `json:"Material"`
-	Diffuse   Ambient `json:"Diffuse"`
-	Ambient   Ambient `json:"Ambient"`
+	Shininess    int     `json:"Shininess"`
+	Specular     Ambient `json:"Specular"`
+	Material     string  `json:"Material"`
+	Diffuse      Ambient `json:"Diffuse"`
+	Ambient      Ambient `json:"Ambient"`
+	Transparency float32 `json:"Transparency,omitempty"`
+	Texture      string  `json:"Texture,omitempty"`
 }
 
 type Ambient struct {
@@ -24,19 +32,222 @@ type Ambient struct {
 	G float32 `json:"G"`
 }
 
-func LoadMaterial(materialDescriptor string) Material {
+// MarshalJSON writes the components with a decimal point, like the bundled
+// materials.json.
+func (c Ambient) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"B":%s,"R":%s,"G":%s}`, formatComponent(c.B), formatComponent(c.R), formatComponent(c.G))), nil
+}
+
+func formatComponent(v float32) string {
+	s := strconv.FormatFloat(float64(v), 'g', -1, 32)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+
+	return s
+}
+
+// MaxShininess is the largest specular exponent accepted in a library.
+const MaxShininess = 1000
+
+// materialEntry is a library entry as written in the file, so that missing
+// fields can be told apart from zero values.
+type materialEntry struct {
+	Material     *string
+	Shininess    *int
+	Ambient      *colorEntry
+	Diffuse      *colorEntry
+	Specular     *colorEntry
+	Transparency *float32
+	Texture      *string
+}
+
+type colorEntry struct {
+	R *float32
+	G *float32
+	B *float32
+}
+
+// LoadMaterial reads a material library and validates every entry. All the
+// problems found are reported together in the error.
+func LoadMaterial(materialDescriptor string) (Material, error) {
 	materialFile, err := os.Open(materialDescriptor)
 	if err != nil {
-		log.Println("Error opening material file:", err)
-		os.Exit(127)
+		return nil, err
 	}
+	defer materialFile.Close()
 
-	material := Material{}
+	entries := []materialEntry{}
 
 	decoder := json.NewDecoder(materialFile)
-	decoder.Decode(&material)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&entries)
+	if err != nil {
+		return nil, errors.Wrap(err, materialDescriptor)
+	}
 
-	log.Println(material)
+	return checkMaterial(materialDescriptor, entries)
+}
+
+// LoadMaterialLibrary reads a Wavefront MTL file when the name ends in .mtl
+// and a JSON library otherwise.
+func LoadMaterialLibrary(materialDescriptor string) (Material, error) {
+	if isMTL(materialDescriptor) {
+		return LoadMTL(materialDescriptor)
+	}
+
+	return LoadMaterial(materialDescriptor)
+}
+
+// SaveMaterialLibrary writes the library in the format LoadMaterialLibrary
+// reads from the name.
+func SaveMaterialLibrary(materialDescriptor string, material Material) error {
+	if isMTL(materialDescriptor) {
+		return SaveMTL(materialDescriptor, material)
+	}
 
-	return material
+	return SaveMaterial(materialDescriptor, material)
+}
+
+func checkMaterial(materialDescriptor string, entries []materialEntry) (Material, error) {
+	material, problems := validateMaterial(entries)
+	if len(material) == 0 {
+		problems = append(problems, "no materials defined")
+	}
+	if len(problems) > 0 {
+		return nil, errors.Errorf("%s: %s", materialDescriptor, strings.Join(problems, "; "))
+	}
+
+	return material, nil
+}
+
+// SaveMaterial writes a material library in the layout of the bundled
+// materials.json, a single line with the keys in the order of the
+// MaterialElement fields and whole numbers written like 1.0. Colors are kept
+// as float32, so digits beyond its precision, like in 0.50196078, are not
+// written back.
+func SaveMaterial(materialDescriptor string, material Material) error {
+	data := bytes.Buffer{}
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(material)
+	if err != nil {
+		return errors.Wrap(err, materialDescriptor)
+	}
+
+	err = ioutil.WriteFile(materialDescriptor, spaceJSON(bytes.TrimSpace(data.Bytes())), 0644)
+	if err != nil {
+		return errors.Wrap(err, materialDescriptor)
+	}
+
+	return nil
+}
+
+// spaceJSON puts a space after every comma and colon outside of strings.
+func spaceJSON(data []byte) []byte {
+	spaced := bytes.Buffer{}
+	inString := false
+	escaped := false
+	for _, c := range data {
+		spaced.WriteByte(c)
+		if escaped {
+			escaped = false
+		} else if inString && c == '\\' {
+			escaped = true
+		} else if c == '"' {
+			inString = !inString
+		} else if !inString && (c == ',' || c == ':') {
+			spaced.WriteByte(' ')
+		}
+	}
+
+	return spaced.Bytes()
+}
+
+func validateMaterial(entries []materialEntry) (Material, []string) {
+	material := Material{}
+	problems := []string{}
+	names := map[string]int{}
+
+	for k, entry := range entries {
+		element := MaterialElement{}
+		name := fmt.Sprintf("material %d", k)
+		if entry.Material == nil || *entry.Material == "" {
+			problems = append(problems, name+": missing Material name")
+		} else {
+			element.Material = *entry.Material
+			name = fmt.Sprintf("material %d %q", k, element.Material)
+			if first, ok := names[element.Material]; ok {
+				problems = append(problems, fmt.Sprintf("%s: duplicate name of material %d", name, first))
+			} else {
+				names[element.Material] = k
+			}
+		}
+
+		if entry.Shininess == nil {
+			problems = append(problems, name+": missing Shininess")
+		} else if *entry.Shininess < 0 || *entry.Shininess > MaxShininess {
+			problems = append(problems, fmt.Sprintf("%s: Shininess %d out of range 0-%d", name, *entry.Shininess, MaxShininess))
+		} else {
+			element.Shininess = *entry.Shininess
+		}
+
+		element.Ambient, problems = validateColor(name+" Ambient", entry.Ambient, problems)
+		element.Diffuse, problems = validateColor(name+" Diffuse", entry.Diffuse, problems)
+		element.Specular, problems = validateColor(name+" Specular", entry.Specular, problems)
+
+		if entry.Transparency != nil {
+			if *entry.Transparency < 0 || *entry.Transparency > 1 {
+				problems = append(problems, fmt.Sprintf("%s: Transparency %g out of range 0-1", name, *entry.Transparency))
+			} else {
+				element.Transparency = *entry.Transparency
+			}
+		}
+		if entry.Texture != nil {
+			element.Texture = *entry.Texture
+		}
+
+		material = append(material, element)
+	}
+
+	return material, problems
+}
+
+func validateColor(name string, entry *colorEntry, problems []string) (Ambient, []string) {
+	color := Ambient{}
+	if entry == nil {
+		return color, append(problems, name+": missing")
+	}
+
+	components := []struct {
+		name  string
+		value *float32
+		set   *float32
+	}{
+		{"R", entry.R, &color.R},
+		{"G", entry.G, &color.G},
+		{"B", entry.B, &color.B},
+	}
+	for _, c := range components {
+		if c.value == nil {
+			problems = append(problems, name+": missing "+c.name)
+		} else if *c.value < 0 || *c.value > 1 {
+			problems = append(problems, fmt.Sprintf("%s: %s %g out of range 0-1", name, c.name, *c.value))
+		} else {
+			*c.set = *c.value
+		}
+	}
+
+	return color, problems
+}
+
+// DefaultMaterials is the built-in library used when none can be loaded.
+func DefaultMaterials() Material {
+	return Material{
+		{Material: "default", Shininess: 32, Ambient: Ambient{R: 0.1, G: 0.1, B: 0.1}, Diffuse: Ambient{R: 0.7, G: 0.7, B: 0.7}, Specular: Ambient{R: 0.5, G: 0.5, B: 0.5}},
+		{Material: "emerald", Shininess: 76, Ambient: Ambient{R: 0.0215, G: 0.1745, B: 0.0215}, Diffuse: Ambient{R: 0.07568, G: 0.61424, B: 0.07568}, Specular: Ambient{R: 0.633, G: 0.727811, B: 0.633}},
+		{Material: "gold", Shininess: 51, Ambient: Ambient{R: 0.24725, G: 0.1995, B: 0.0745}, Diffuse: Ambient{R: 0.75164, G: 0.60648, B: 0.22648}, Specular: Ambient{R: 0.628281, G: 0.555802, B: 0.366065}},
+		{Material: "ruby", Shininess: 76, Ambient: Ambient{R: 0.1745, G: 0.01175, B: 0.01175}, Diffuse: Ambient{R: 0.61424, G: 0.04136, B: 0.04136}, Specular: Ambient{R: 0.727811, G: 0.626959, B: 0.626959}},
+		{Material: "rubber", Shininess: 10, Ambient: Ambient{R: 0.02, G: 0.02, B: 0.02}, Diffuse: Ambient{R: 0.01, G: 0.01, B: 0.01}, Specular: Ambient{R: 0.4, G: 0.4, B: 0.4}},
+	}
 }
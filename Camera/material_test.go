@@ -0,0 +1,108 @@
+package Camera
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := ioutil.WriteFile(path, []byte(data), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+const testColor = `{"R": 0.1, "G": 0.2, "B": 0.3}`
+
+func testMaterialEntry(name, extra string) string {
+	return `{"Material": "` + name + `", "Shininess": 10, "Ambient": ` + testColor + `, "Diffuse": ` + testColor + `, "Specular": ` + testColor + extra + `}`
+}
+
+func TestLoadBundledMaterials(t *testing.T) {
+	material, err := LoadMaterial("../materials.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(material) == 0 || material[0].Material != "emerald" || material[0].Shininess != 76 {
+		t.Errorf("first material %+v, want emerald with shininess 76", material[0])
+	}
+}
+
+func TestValidateMaterial(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		problems []string
+	}{
+		{"valid", `[` + testMaterialEntry("a", "") + `, ` + testMaterialEntry("b", `, "Transparency": 0.5, "Texture": "b.png"`) + `]`, nil},
+		{"duplicate", `[` + testMaterialEntry("a", "") + `, ` + testMaterialEntry("a", "") + `]`,
+			[]string{`material 1 "a": duplicate name of material 0`}},
+		{"missing name", `[{"Shininess": 1, "Ambient": ` + testColor + `, "Diffuse": ` + testColor + `, "Specular": ` + testColor + `}]`,
+			[]string{"material 0: missing Material name"}},
+		{"missing fields", `[{"Material": "a", "Ambient": {"R": 0.1, "G": 0.2}}]`,
+			[]string{`"a": missing Shininess`, `"a" Ambient: missing B`, `"a" Diffuse: missing`, `"a" Specular: missing`}},
+		{"ranges", `[{"Material": "a", "Shininess": 1001, "Ambient": {"R": -0.1, "G": 0, "B": 1.5}, "Diffuse": ` + testColor + `, "Specular": ` + testColor + `, "Transparency": 2}]`,
+			[]string{"Shininess 1001 out of range 0-1000", "Ambient: R -0.1 out of range 0-1", "Ambient: B 1.5 out of range 0-1", "Transparency 2 out of range 0-1"}},
+		{"empty", `[]`, []string{"no materials defined"}},
+		{"unknown field", `[` + testMaterialEntry("a", `, "Glossiness": 1`) + `]`, []string{`unknown field "Glossiness"`}},
+		{"malformed", `[{"Material": }]`, []string{"invalid character"}},
+	}
+
+	for _, c := range cases {
+		material, err := LoadMaterial(writeFile(t, "materials.json", c.data))
+		if len(c.problems) == 0 {
+			if err != nil {
+				t.Errorf("%s: %v", c.name, err)
+			} else if len(material) != 2 || material[1].Texture != "b.png" || material[1].Transparency != 0.5 {
+				t.Errorf("%s: materials %+v", c.name, material)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("%s: no error", c.name)
+			continue
+		}
+		for _, problem := range c.problems {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("%s: error %q does not report %q", c.name, err, problem)
+			}
+		}
+	}
+}
+
+func TestSaveMaterialRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "materials.json")
+	err := SaveMaterial(path, DefaultMaterials())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	material, err := LoadMaterial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(material, DefaultMaterials()) {
+		t.Errorf("loaded %+v, want the built-in materials", material)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if !strings.HasPrefix(string(data), `[{"Shininess": 32, "Specular": {"B": 0.5, "R": 0.5, "G": 0.5}, "Material": "default"`) {
+		t.Errorf("saved %s, want the layout of materials.json", data)
+	}
+}
+
+func TestFormatComponent(t *testing.T) {
+	cases := map[float32]string{0: "0.0", 1: "1.0", 0.5: "0.5", 0.727811: "0.727811", 1e-07: "1e-07"}
+	for v, want := range cases {
+		if got := formatComponent(v); got != want {
+			t.Errorf("formatComponent(%v) = %s, want %s", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package Camera
+
+import (
+	"fmt"
+	"log"
+)
+
+const (
+	MaterialAmbient = iota
+	MaterialDiffuse
+	MaterialSpecular
+)
+
+var MaterialPropertyNames = []string{"ambient", "diffuse", "specular"}
+
+// SelectedMaterialElement returns the material being drawn, false when the
+// library is empty.
+func (s *SphereWorld) SelectedMaterialElement() (*MaterialElement, bool) {
+	if len(s.Materials) == 0 {
+		return nil, false
+	}
+
+	return &s.Materials[s.SelectedMaterial], true
+}
+
+// ModifyMaterial adds r, g and b to the given color property and shininess to
+// the specular exponent of the selected material, keeping them in the ranges
+// LoadMaterial accepts.
+func (s *SphereWorld) ModifyMaterial(property int, r, g, b float32, shininess int) {
+	mat, ok := s.SelectedMaterialElement()
+	if !ok {
+		log.Println("No materials loaded")
+		return
+	}
+
+	color := &mat.Ambient
+	if property == MaterialDiffuse {
+		color = &mat.Diffuse
+	} else if property == MaterialSpecular {
+		color = &mat.Specular
+	}
+	color.R = clampUnit(color.R + r)
+	color.G = clampUnit(color.G + g)
+	color.B = clampUnit(color.B + b)
+	mat.Shininess = clampInt(mat.Shininess+shininess, 0, MaxShininess)
+
+	s.Prepared = true
+	s.Invalidate()
+
+	log.Printf(`
+	Material  ---> %s
+	Ambient   ---> R %.3f G %.3f B %.3f
+	Diffuse   ---> R %.3f G %.3f B %.3f
+	Specular  ---> R %.3f G %.3f B %.3f
+	Shininess ---> %d
+	`, mat.Material,
+		mat.Ambient.R, mat.Ambient.G, mat.Ambient.B,
+		mat.Diffuse.R, mat.Diffuse.G, mat.Diffuse.B,
+		mat.Specular.R, mat.Specular.G, mat.Specular.B,
+		mat.Shininess)
+}
+
+// CopyMaterial appends a copy of the selected material under a new name and
+// selects it.
+func (s *SphereWorld) CopyMaterial() {
+	mat, ok := s.SelectedMaterialElement()
+	if !ok {
+		log.Println("No materials loaded")
+		return
+	}
+
+	copied := *mat
+	copied.Material = s.unusedMaterialName(mat.Material + " copy")
+	s.Materials = append(s.Materials, copied)
+	s.SelectedMaterial = len(s.Materials) - 1
+
+	s.Prepared = true
+	s.Invalidate()
+	log.Println("Current material --->", copied.Material)
+}
+
+func (s *SphereWorld) unusedMaterialName(name string) string {
+	used := map[string]bool{}
+	for _, mat := range s.Materials {
+		used[mat.Material] = true
+	}
+
+	unused := name
+	for k := 2; used[unused]; k++ {
+		unused = fmt.Sprintf("%s %d", name, k)
+	}
+
+	return unused
+}
+
+// UseMaterials draws the sphere with the selected material instead of the
+// Ka, Kd, Ks and Hue constants.
+func (s *SphereWorld) UseMaterials() {
+	if s.Prepared || len(s.Materials) == 0 {
+		return
+	}
+
+	s.Prepared = true
+	s.Invalidate()
+	log.Println("Current material --->", s.Materials[s.SelectedMaterial].Material)
+}
+
+func clampUnit(v float32) float32 {
+	if v < 0 {
+		return 0
+	} else if v > 1 {
+		return 1
+	}
+
+	return v
+}
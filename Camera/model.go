@@ -0,0 +1,180 @@
+package Camera
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ShadingModel is a reflection model. Reflect returns the diffuse and the
+// specular factor for a surface with normal lit along lightVec and watched
+// along watchVec, which points from the surface to the viewer, and false when
+// the surface faces away from the light or the viewer. The factors are scaled
+// by the diffuse and specular colors of the material.
+type ShadingModel interface {
+	Name() string
+	Reflect(normal, lightVec, watchVec mgl32.Vec3, shininess float32) (float32, float32, bool)
+}
+
+var ShadingModels = []ShadingModel{
+	Phong{},
+	BlinnPhong{},
+	Lambert{},
+	OrenNayar{Roughness: 0.5},
+	CookTorrance{Fresnel: 0.04},
+}
+
+func FindShadingModel(name string) (int, bool) {
+	for k, model := range ShadingModels {
+		if model.Name() == name {
+			return k, true
+		}
+	}
+
+	return 0, false
+}
+
+func ShadingModelNames() []string {
+	names := []string{}
+	for _, model := range ShadingModels {
+		names = append(names, model.Name())
+	}
+
+	return names
+}
+
+type Phong struct{}
+
+func (Phong) Name() string {
+	return "phong"
+}
+
+func (Phong) Reflect(normal, lightVec, watchVec mgl32.Vec3, shininess float32) (float32, float32, bool) {
+	dif, ref, ok := PhongTerms(normal, lightVec, watchVec)
+	return dif, float32(math.Pow(ref, float64(shininess))), ok
+}
+
+// BlinnPhong replaces the reflected vector of Phong with the half vector
+// between the light and the viewer.
+type BlinnPhong struct{}
+
+func (BlinnPhong) Name() string {
+	return "blinn-phong"
+}
+
+func (BlinnPhong) Reflect(normal, lightVec, watchVec mgl32.Vec3, shininess float32) (float32, float32, bool) {
+	dif, ok := facing(normal, lightVec, watchVec)
+	if !ok {
+		return 0, 0, false
+	}
+
+	half := watchVec.Sub(lightVec).Normalize()
+	return dif, float32(math.Pow(float64(clampZero(normal.Dot(half))), float64(shininess))), true
+}
+
+// Lambert is the ideal matte surface without any highlight.
+type Lambert struct{}
+
+func (Lambert) Name() string {
+	return "lambert"
+}
+
+func (Lambert) Reflect(normal, lightVec, watchVec mgl32.Vec3, shininess float32) (float32, float32, bool) {
+	dif, ok := facing(normal, lightVec, watchVec)
+	return dif, 0, ok
+}
+
+// OrenNayar is a diffuse model of rough surfaces, Roughness being the
+// standard deviation of the facet slopes in radians. It has no highlight.
+type OrenNayar struct {
+	Roughness float32
+}
+
+func (OrenNayar) Name() string {
+	return "oren-nayar"
+}
+
+func (m OrenNayar) Reflect(normal, lightVec, watchVec mgl32.Vec3, shininess float32) (float32, float32, bool) {
+	dif, ok := facing(normal, lightVec, watchVec)
+	if !ok {
+		return 0, 0, false
+	}
+
+	sigma2 := float64(m.Roughness * m.Roughness)
+	a := 1 - 0.5*sigma2/(sigma2+0.33)
+	b := 0.45 * sigma2 / (sigma2 + 0.09)
+
+	toLight := lightVec.Mul(-1)
+	cosI := float64(dif)
+	cosR := float64(clampZero(normal.Dot(watchVec)))
+	thetaI := math.Acos(math.Min(cosI, 1))
+	thetaR := math.Acos(math.Min(cosR, 1))
+	alpha := math.Max(thetaI, thetaR)
+	beta := math.Min(thetaI, thetaR)
+
+	// cosine of the azimuth between light and viewer projected on the surface
+	lightTangent := toLight.Sub(normal.Mul(toLight.Dot(normal)))
+	watchTangent := watchVec.Sub(normal.Mul(watchVec.Dot(normal)))
+	cosPhi := 0.0
+	if lightTangent.Len() > 0 && watchTangent.Len() > 0 {
+		cosPhi = math.Max(0, float64(lightTangent.Normalize().Dot(watchTangent.Normalize())))
+	}
+
+	return float32(cosI * (a + b*cosPhi*math.Sin(alpha)*math.Tan(beta))), 0, true
+}
+
+// CookTorrance is a microfacet model with the Beckmann distribution, whose
+// roughness follows from the shininess, the Schlick approximation of Fresnel
+// reflectance with Fresnel at normal incidence and the Torrance-Sparrow
+// masking term.
+type CookTorrance struct {
+	Fresnel float32
+}
+
+func (CookTorrance) Name() string {
+	return "cook-torrance"
+}
+
+func (m CookTorrance) Reflect(normal, lightVec, watchVec mgl32.Vec3, shininess float32) (float32, float32, bool) {
+	dif, ok := facing(normal, lightVec, watchVec)
+	if !ok {
+		return 0, 0, false
+	}
+
+	nl := float64(dif)
+	nv := float64(clampZero(normal.Dot(watchVec)))
+	if nl == 0 || nv == 0 {
+		return dif, 0, true
+	}
+
+	half := watchVec.Sub(lightVec).Normalize()
+	nh := float64(clampZero(normal.Dot(half)))
+	vh := float64(clampZero(watchVec.Dot(half)))
+	if nh == 0 || vh == 0 {
+		return dif, 0, true
+	}
+
+	m2 := 2 / (float64(shininess) + 2)
+	nh2 := nh * nh
+	d := math.Exp((nh2-1)/(m2*nh2)) / (math.Pi * m2 * nh2 * nh2)
+	f := float64(m.Fresnel) + (1-float64(m.Fresnel))*math.Pow(1-vh, 5)
+	g := math.Min(1, math.Min(2*nh*nv/vh, 2*nh*nl/vh))
+
+	return dif, float32(d * f * g / (4 * nl * nv)), true
+}
+
+func facing(normal, lightVec, watchVec mgl32.Vec3) (float32, bool) {
+	if normal.Dot(watchVec) < 0 || normal.Dot(lightVec.Mul(-1)) < 0 {
+		return 0, false
+	}
+
+	return clampZero(normal.Dot(lightVec.Mul(-1))), true
+}
+
+func clampZero(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+
+	return v
+}
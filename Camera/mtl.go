@@ -0,0 +1,280 @@
+package Camera
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kanister10l/GoCamera/World"
+	"github.com/pkg/errors"
+)
+
+func isMTL(materialDescriptor string) bool {
+	return strings.EqualFold(filepath.Ext(materialDescriptor), ".mtl")
+}
+
+// LoadMTL reads a Wavefront material library, as referenced by the mtllib
+// statement of an OBJ file. Ka, Kd and Ks become the colors, Ns the
+// shininess, d or Tr the transparency and map_Kd the texture, resolved
+// relative to the library. Colors and shininess a material leaves out are
+// zero, and other statements are ignored.
+func LoadMTL(mtlDescriptor string) (Material, error) {
+	mtlFile, err := os.Open(mtlDescriptor)
+	if err != nil {
+		return nil, err
+	}
+	defer mtlFile.Close()
+
+	entries, err := parseMTL(mtlFile, filepath.Dir(mtlDescriptor))
+	if err != nil {
+		return nil, errors.Wrap(err, mtlDescriptor)
+	}
+
+	return checkMaterial(mtlDescriptor, entries)
+}
+
+// LoadWorldMaterials loads the material libraries of the "obj" objects of
+// world by path. Libraries which fail to load are logged and skipped.
+func LoadWorldMaterials(world *World.World) map[string]Material {
+	libraries := map[string]Material{}
+	for _, library := range world.MaterialLibraries {
+		loaded, err := LoadMTL(library)
+		if err != nil {
+			log.Println("Error loading obj materials:", err)
+			continue
+		}
+		libraries[library] = loaded
+	}
+
+	return libraries
+}
+
+func parseMTL(r io.Reader, dir string) ([]materialEntry, error) {
+	entries := []materialEntry{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if k := strings.Index(text, "#"); k != -1 {
+			text = text[:k]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "newmtl" {
+			name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "newmtl"))
+			shininess := 0
+			entries = append(entries, materialEntry{
+				Material:  &name,
+				Shininess: &shininess,
+				Ambient:   mtlColor(0, 0, 0),
+				Diffuse:   mtlColor(0, 0, 0),
+				Specular:  mtlColor(0, 0, 0),
+			})
+			continue
+		}
+
+		if len(entries) == 0 {
+			if isMTLStatement(fields[0]) {
+				return nil, errors.Errorf("line %d: %s before newmtl", line, fields[0])
+			}
+			continue
+		}
+
+		entry := &entries[len(entries)-1]
+		var err error
+		switch fields[0] {
+		case "Ka":
+			entry.Ambient, err = parseMTLColor(fields[1:])
+		case "Kd":
+			entry.Diffuse, err = parseMTLColor(fields[1:])
+		case "Ks":
+			entry.Specular, err = parseMTLColor(fields[1:])
+		case "Ns":
+			var ns float32
+			ns, err = parseMTLFloat(fields[1:])
+			shininess := int(math.Round(float64(ns)))
+			entry.Shininess = &shininess
+		case "d":
+			var d float32
+			d, err = parseMTLFloat(fields[1:])
+			transparency := 1 - d
+			entry.Transparency = &transparency
+		case "Tr":
+			var transparency float32
+			transparency, err = parseMTLFloat(fields[1:])
+			entry.Transparency = &transparency
+		case "map_Kd":
+			var texture string
+			texture, err = parseMTLTexture(strings.TrimPrefix(strings.TrimSpace(text), "map_Kd"))
+			if err != nil {
+				break
+			}
+			if !filepath.IsAbs(texture) {
+				texture = filepath.Join(dir, texture)
+			}
+			entry.Texture = &texture
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d: %s", line, fields[0])
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+func isMTLStatement(keyword string) bool {
+	switch keyword {
+	case "Ka", "Kd", "Ks", "Ns", "d", "Tr", "map_Kd":
+		return true
+	}
+
+	return false
+}
+
+func mtlColor(r, g, b float32) *colorEntry {
+	return &colorEntry{R: &r, G: &g, B: &b}
+}
+
+// parseMTLColor reads the r g b of a color statement, a single value being
+// gray. Spectral and CIE XYZ colors are not supported.
+func parseMTLColor(fields []string) (*colorEntry, error) {
+	if len(fields) > 0 && (fields[0] == "spectral" || fields[0] == "xyz") {
+		return nil, errors.Errorf("%s colors are not supported", fields[0])
+	}
+	if len(fields) != 1 && len(fields) != 3 {
+		return nil, errors.Errorf("expected 1 or 3 values, got %d", len(fields))
+	}
+
+	values := make([]float32, len(fields))
+	for k, field := range fields {
+		v, err := strconv.ParseFloat(field, 32)
+		if err != nil {
+			return nil, err
+		}
+		values[k] = float32(v)
+	}
+	if len(values) == 1 {
+		return mtlColor(values[0], values[0], values[0]), nil
+	}
+
+	return mtlColor(values[0], values[1], values[2]), nil
+}
+
+// parseMTLFloat reads the last value of a statement, skipping options like
+// the -halo of d.
+func parseMTLFloat(fields []string) (float32, error) {
+	if len(fields) == 0 {
+		return 0, errors.New("missing value")
+	}
+
+	v, err := strconv.ParseFloat(fields[len(fields)-1], 32)
+	return float32(v), err
+}
+
+// mtlTextureOptions are the options of texture statements with the least and
+// the most values they take.
+var mtlTextureOptions = map[string][2]int{
+	"-blendu":  {1, 1},
+	"-blendv":  {1, 1},
+	"-bm":      {1, 1},
+	"-boost":   {1, 1},
+	"-cc":      {1, 1},
+	"-clamp":   {1, 1},
+	"-imfchan": {1, 1},
+	"-mm":      {2, 2},
+	"-o":       {1, 3},
+	"-s":       {1, 3},
+	"-t":       {1, 3},
+	"-texres":  {1, 1},
+}
+
+// parseMTLTexture returns the path of a texture statement, skipping the
+// options before it. The path is the rest of the line and may contain spaces.
+func parseMTLTexture(text string) (string, error) {
+	rest := strings.TrimSpace(text)
+	for strings.HasPrefix(rest, "-") {
+		var option string
+		option, rest = nextField(rest)
+		values, ok := mtlTextureOptions[option]
+		if !ok {
+			return "", errors.Errorf("unknown option %s", option)
+		}
+
+		for k := 0; k < values[1]; k++ {
+			value, after := nextField(rest)
+			if k >= values[0] {
+				// the optional values of -o, -s and -t are numbers
+				if _, err := strconv.ParseFloat(value, 32); err != nil {
+					break
+				}
+			}
+			if value == "" {
+				return "", errors.Errorf("missing value of %s", option)
+			}
+			rest = after
+		}
+	}
+
+	if rest == "" {
+		return "", errors.New("missing texture path")
+	}
+
+	return rest, nil
+}
+
+// nextField splits the first field off text.
+func nextField(text string) (string, string) {
+	text = strings.TrimLeft(text, " \t")
+	k := strings.IndexAny(text, " \t")
+	if k == -1 {
+		return text, ""
+	}
+
+	return text[:k], strings.TrimLeft(text[k:], " \t")
+}
+
+// SaveMTL writes a material library as a Wavefront MTL file. Textures are
+// written relative to the file where possible.
+func SaveMTL(mtlDescriptor string, material Material) error {
+	dir := filepath.Dir(mtlDescriptor)
+	formatColor := func(c Ambient) string {
+		return fmt.Sprintf("%g %g %g", c.R, c.G, c.B)
+	}
+
+	mtl := bytes.Buffer{}
+	for k, mat := range material {
+		if k > 0 {
+			mtl.WriteString("\n")
+		}
+		fmt.Fprintf(&mtl, "newmtl %s\n", mat.Material)
+		fmt.Fprintf(&mtl, "Ka %s\n", formatColor(mat.Ambient))
+		fmt.Fprintf(&mtl, "Kd %s\n", formatColor(mat.Diffuse))
+		fmt.Fprintf(&mtl, "Ks %s\n", formatColor(mat.Specular))
+		fmt.Fprintf(&mtl, "Ns %d\n", mat.Shininess)
+		fmt.Fprintf(&mtl, "d %g\n", 1-mat.Transparency)
+		if mat.Texture != "" {
+			texture := mat.Texture
+			if rel, err := filepath.Rel(dir, texture); err == nil && !filepath.IsAbs(texture) {
+				texture = rel
+			}
+			fmt.Fprintf(&mtl, "map_Kd %s\n", texture)
+		}
+	}
+
+	err := ioutil.WriteFile(mtlDescriptor, mtl.Bytes(), 0644)
+	if err != nil {
+		return errors.Wrap(err, mtlDescriptor)
+	}
+
+	return nil
+}
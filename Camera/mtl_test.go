@@ -0,0 +1,101 @@
+package Camera
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMTL(t *testing.T) {
+	tests := []struct {
+		name         string
+		mtl          string
+		diffuse      Ambient
+		shininess    int
+		transparency float32
+		texture      string
+		err          bool
+	}{
+		{name: "colors", mtl: "newmtl red\nKa 0.1 0 0\nKd 0.8 0 0\nKs 1\nNs 32.6\n", diffuse: Ambient{R: 0.8}, shininess: 33},
+		{name: "comments", mtl: "# library\nnewmtl red # the red one\nKd 0.8 0 0 # diffuse\n", diffuse: Ambient{R: 0.8}},
+		{name: "gray", mtl: "newmtl gray\nKd 0.5\n", diffuse: Ambient{R: 0.5, G: 0.5, B: 0.5}},
+		{name: "dissolve", mtl: "newmtl glass\nd 0.25\n", transparency: 0.75},
+		{name: "dissolve halo", mtl: "newmtl glass\nd -halo 0.25\n", transparency: 0.75},
+		{name: "transparency", mtl: "newmtl glass\nTr 0.25\n", transparency: 0.25},
+		{name: "texture", mtl: "newmtl wood\nmap_Kd wood.png\n", texture: "wood.png"},
+		{name: "texture options", mtl: "newmtl wood\nmap_Kd -s 2 2 1 -clamp on -mm 0 1 wood.png\n", texture: "wood.png"},
+		{name: "texture short option", mtl: "newmtl wood\nmap_Kd -o 0.5 wood.png\n", texture: "wood.png"},
+		{name: "texture spaces", mtl: "newmtl wood\nmap_Kd -bm 1 dark oak/wood grain.png \n", texture: "dark oak/wood grain.png"},
+		{name: "unknown texture option", mtl: "newmtl wood\nmap_Kd -foo 1 wood.png\n", err: true},
+		{name: "missing texture", mtl: "newmtl wood\nmap_Kd -s 2\n", err: true},
+		{name: "statement before newmtl", mtl: "Kd 1 1 1\nnewmtl white\n", err: true},
+		{name: "other statement before newmtl", mtl: "illum 2\nnewmtl white\nKd 1 1 1\n", diffuse: Ambient{R: 1, G: 1, B: 1}},
+		{name: "spectral", mtl: "newmtl sky\nKd spectral sky.rfl\n", err: true},
+		{name: "two values", mtl: "newmtl bad\nKd 1 1\n", err: true},
+		{name: "bad number", mtl: "newmtl bad\nNs many\n", err: true},
+	}
+
+	for _, test := range tests {
+		entries, err := parseMTL(strings.NewReader(test.mtl), "lib")
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(entries) != 1 {
+			t.Errorf("%s: got %d materials", test.name, len(entries))
+			continue
+		}
+
+		material, err := checkMaterial(test.name, entries)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		mat := material[0]
+		if mat.Diffuse != test.diffuse || mat.Shininess != test.shininess {
+			t.Errorf("%s: got diffuse %+v shininess %d", test.name, mat.Diffuse, mat.Shininess)
+		}
+		if d := mat.Transparency - test.transparency; d > 1e-6 || d < -1e-6 {
+			t.Errorf("%s: got transparency %v, want %v", test.name, mat.Transparency, test.transparency)
+		}
+		texture := test.texture
+		if texture != "" {
+			texture = filepath.Join("lib", texture)
+		}
+		if mat.Texture != texture {
+			t.Errorf("%s: got texture %q, want %q", test.name, mat.Texture, texture)
+		}
+	}
+}
+
+func TestMTLRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "round.mtl")
+	material := Material{{
+		Material:     "wood",
+		Shininess:    12,
+		Ambient:      Ambient{R: 0.1, G: 0.1, B: 0.1},
+		Diffuse:      Ambient{R: 0.5, G: 0.25, B: 0},
+		Specular:     Ambient{R: 1, G: 1, B: 1},
+		Transparency: 0.5,
+		Texture:      filepath.Join(dir, "dark oak", "wood grain.png"),
+	}}
+
+	err := SaveMTL(path, material)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadMTL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0] != material[0] {
+		t.Errorf("got %+v, want %+v", loaded, material)
+	}
+}
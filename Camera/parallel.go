@@ -0,0 +1,54 @@
+package Camera
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Workers is the number of goroutines sharing the sphere lighting and polygon
+// generation, 1 runs them serially.
+var Workers = runtime.NumCPU()
+
+// parallelChunk is the least number of items worth handing to a worker.
+const parallelChunk = 256
+
+// parallelFor calls work for consecutive chunks of [0, n) on a pool of
+// Workers goroutines and waits for all of them. Chunks never overlap, so work
+// may write to its own items without locking.
+func parallelFor(n int, work func(start, end int)) {
+	workers := Workers
+	if workers > (n+parallelChunk-1)/parallelChunk {
+		workers = (n + parallelChunk - 1) / parallelChunk
+	}
+	if workers <= 1 {
+		work(0, n)
+		return
+	}
+
+	size := (n + 4*workers - 1) / (4 * workers)
+	if size < parallelChunk {
+		size = parallelChunk
+	}
+
+	chunks := make(chan int, (n+size-1)/size)
+	for start := 0; start < n; start += size {
+		chunks <- start
+	}
+	close(chunks)
+
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				end := start + size
+				if end > n {
+					end = n
+				}
+				work(start, end)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,74 @@
+package Camera
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/kanister10l/GoCamera/World"
+)
+
+func testSphere(prepared bool) (*Camera, *SphereMesh, *SphereWorld) {
+	camera := NewCameraAt(0, 0, 0, 75, 1)
+	mesh := NewSphereMesh(World.UVSphere(World.Origin{X: 0, Y: 0, Z: 20}, 10, 40, 80))
+	sp := CreateSphereWorld(0, 0, 20, 30, DefaultMaterials())
+	sp.AddLight()
+	sp.AddLight()
+	sp.Prepared = prepared
+	sp.SelectedMaterial = 2
+
+	return camera, mesh, sp
+}
+
+// parallelWorkers is at least 4 so that the pool is used on a single CPU too.
+func parallelWorkers() int {
+	if runtime.NumCPU() < 4 {
+		return 4
+	}
+
+	return runtime.NumCPU()
+}
+
+func TestParallelMatchesSerial(t *testing.T) {
+	workers := Workers
+	defer func() {
+		Workers = workers
+	}()
+
+	for _, prepared := range []bool{false, true} {
+		camera, mesh, sp := testSphere(prepared)
+
+		Workers = 1
+		serial := camera.SpherePolygons(mesh, sp)
+		Workers = parallelWorkers()
+		parallel := camera.SpherePolygons(mesh, sp)
+
+		if len(serial) != len(mesh.Faces) {
+			t.Fatalf("prepared %t: %d polygons, want %d", prepared, len(serial), len(mesh.Faces))
+		}
+		if !reflect.DeepEqual(serial, parallel) {
+			t.Errorf("prepared %t: parallel polygons differ from serial ones", prepared)
+		}
+	}
+}
+
+func benchmarkSpherePolygons(b *testing.B, workers int) {
+	defer func(w int) {
+		Workers = w
+	}(Workers)
+	Workers = workers
+
+	camera, mesh, sp := testSphere(true)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		camera.SpherePolygons(mesh, sp)
+	}
+}
+
+func BenchmarkSpherePolygonsSerial(b *testing.B) {
+	benchmarkSpherePolygons(b, 1)
+}
+
+func BenchmarkSpherePolygonsParallel(b *testing.B) {
+	benchmarkSpherePolygons(b, runtime.NumCPU())
+}
@@ -0,0 +1,36 @@
+package Camera
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/kanister10l/GoCamera/Helpers"
+	"github.com/kanister10l/GoCamera/World"
+)
+
+// ScreenRay returns the ray from the camera position through the window
+// coordinate x, y measured in pixels from the top left corner. It inverts the
+// angular projection of CheckVisibility, so the ray direction is normalized
+// and hit distances are in world units.
+func (camera *Camera) ScreenRay(x, y, width, height float32) World.Ray {
+	nx := 2*x/width - 1
+	ny := 1 - 2*y/height
+
+	hTan := float32(math.Tan(float64(Helpers.DegToRad(nx * camera.HorizontalFov / 2))))
+	vTan := float32(math.Tan(float64(Helpers.DegToRad(ny * camera.VerticalFov / 2))))
+
+	xAxis := mgl32.Vec3{camera.XVector[0], camera.XVector[1], camera.XVector[2]}
+	yAxis := mgl32.Vec3{camera.YVector[0], camera.YVector[1], camera.YVector[2]}
+	zAxis := mgl32.Vec3{camera.ZVector[0], camera.ZVector[1], camera.ZVector[2]}
+	direction := zAxis.Add(xAxis.Mul(hTan)).Sub(yAxis.Mul(vTan)).Normalize()
+
+	return World.Ray{
+		Origin:    World.Origin{X: camera.X, Y: camera.Y, Z: camera.Z},
+		Direction: World.Origin{X: direction[0], Y: direction[1], Z: direction[2]},
+	}
+}
+
+// Pick returns the closest visible entity face under the window coordinate.
+func (camera *Camera) Pick(world *World.World, x, y, width, height float32) (World.Hit, bool) {
+	return world.Raycast(camera.ScreenRay(x, y, width, height))
+}
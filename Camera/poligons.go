@@ -1,8 +1,12 @@
 package Camera
 
 type Polygon struct {
-	Drawer []float32
-	Dist   float32
+	Drawer    []float32
+	Color     []float32
+	Positions []float32
+	Normals   []float32
+	Material  *MaterialElement
+	Dist      float32
 }
 
 func MakePolygon(drawer []float32) Polygon {
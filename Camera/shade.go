@@ -0,0 +1,318 @@
+package Camera
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	"github.com/go-gl/gl/v4.1-compatibility/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/kanister10l/GoCamera/World"
+)
+
+const (
+	ShadingFlat = iota
+	ShadingGouraud
+	ShadingPhong
+)
+
+var ShadingNames = []string{"flat", "gouraud", "phong"}
+
+// PhongMaxLights is the size of the light arrays in the per-pixel shader.
+const PhongMaxLights = 8
+
+var DefaultColor = World.Color{R: 0.8, G: 0.8, B: 0.8}
+
+// PhongTerms returns the diffuse and the specular cosine of the Phong model
+// for a surface with normal lit along lightVec and watched along watchVec,
+// which points from the surface to the viewer. It reports false when the
+// surface faces away from the light or the viewer.
+func PhongTerms(normal, lightVec, watchVec mgl32.Vec3) (float32, float64, bool) {
+	if normal.Dot(watchVec) < 0 || normal.Dot(lightVec.Mul(-1)) < 0 {
+		return 0, 0, false
+	}
+
+	reflectionVec := lightVec.Sub(normal.Mul(lightVec.Dot(normal) * 2))
+	ref := float64(reflectionVec.Dot(watchVec))
+	dif := normal.Dot(lightVec.Mul(-1))
+	if ref < 0 {
+		ref = 0
+	}
+	if dif < 0 {
+		dif = 0
+	}
+
+	return dif, ref, true
+}
+
+// ColorMaterial makes a matte material out of a plain color.
+func ColorMaterial(c World.Color) MaterialElement {
+	return MaterialElement{
+		Material:  "color",
+		Shininess: 1,
+		Ambient:   Ambient{R: c.R * 0.2, G: c.G * 0.2, B: c.B * 0.2},
+		Diffuse:   Ambient{R: c.R * 0.8, G: c.G * 0.8, B: c.B * 0.8},
+	}
+}
+
+// Find returns the material with the given name.
+func (m Material) Find(name string) (MaterialElement, bool) {
+	for _, v := range m {
+		if v.Material == name {
+			return v, true
+		}
+	}
+
+	return MaterialElement{}, false
+}
+
+// WorldMaterials are the materials world entities are drawn with, the
+// material library and the mtllib libraries of "obj" objects by path.
+type WorldMaterials struct {
+	Library   Material
+	Libraries map[string]Material
+}
+
+// Shadowed lists the materials of the mtllib libraries which the material
+// library names as well. Objects using such a name get the one of their own
+// library.
+func (m WorldMaterials) Shadowed() []string {
+	shadowed := []string{}
+	for path, library := range m.Libraries {
+		for _, mat := range library {
+			if _, ok := m.Library.Find(mat.Material); ok {
+				shadowed = append(shadowed, mat.Material+" in "+path)
+			}
+		}
+	}
+	sort.Strings(shadowed)
+
+	return shadowed
+}
+
+// EntityMaterial returns the material named by the entity, looked up in the
+// libraries of the entity before the material library. Entities without a
+// known material use their color, or DefaultColor.
+func EntityMaterial(entity *World.Entity, materials WorldMaterials) MaterialElement {
+	if entity.Material != "" {
+		for _, library := range entity.Libraries {
+			if mat, ok := materials.Libraries[library].Find(entity.Material); ok {
+				return mat
+			}
+		}
+		if mat, ok := materials.Library.Find(entity.Material); ok {
+			return mat
+		}
+	}
+
+	if entity.Color != nil {
+		return ColorMaterial(*entity.Color)
+	}
+
+	return ColorMaterial(DefaultColor)
+}
+
+// FaceNormal returns the normal of a counter-clockwise wound triangle.
+func FaceNormal(p1, p2, p3 World.Point) mgl32.Vec3 {
+	e1 := mgl32.Vec3{p2.X - p1.X, p2.Y - p1.Y, p2.Z - p1.Z}
+	e2 := mgl32.Vec3{p3.X - p1.X, p3.Y - p1.Y, p3.Z - p1.Z}
+	normal := e1.Cross(e2)
+	if normal.Len() == 0 {
+		return normal
+	}
+
+	return normal.Normalize()
+}
+
+// SceneLights returns the world lights, or a white light carried by the camera
+// when there are none.
+func (camera *Camera) SceneLights(lights []World.Light) []World.Light {
+	if len(lights) == 0 {
+		return []World.Light{{Type: World.LightPoint, Position: World.Origin{X: camera.X, Y: camera.Y, Z: camera.Z}, Color: World.Color{R: 1, G: 1, B: 1}, Intensity: 1}}
+	}
+
+	return lights
+}
+
+func (camera *Camera) NextShadingMode() {
+	camera.ShadingMode = (camera.ShadingMode + 1) % len(ShadingNames)
+	log.Println("Shading --->", ShadingNames[camera.ShadingMode])
+	if camera.ShadingMode == ShadingPhong && camera.PhongProgram == 0 {
+		log.Println("Phong shader not available, drawing gouraud")
+	}
+}
+
+func FindShading(name string) (int, bool) {
+	for k, v := range ShadingNames {
+		if v == name {
+			return k, true
+		}
+	}
+
+	return 0, false
+}
+
+// ShadeSurface lights a surface point with the shading model. Lights of the
+// world are summed with their attenuation and spot cones, and without any the
+// camera carries a white light. The material comes from LightingMaterial, the
+// lights are decoded by LightingLights and the result is encoded by
+// OutputColor. Surfaces facing away get only the ambient term.
+func (camera *Camera) ShadeSurface(point, normal mgl32.Vec3, mat MaterialElement, lights []World.Light, model ShadingModel) []float32 {
+	eye := mgl32.Vec3{camera.X, camera.Y, camera.Z}
+	lights = camera.LightingLights(camera.SceneLights(lights))
+
+	watchVec := eye.Sub(point).Normalize()
+	color := []float32{mat.Ambient.R, mat.Ambient.G, mat.Ambient.B}
+	for _, light := range lights {
+		direction, factor, ok := light.Illuminate(World.Origin{X: point.X(), Y: point.Y(), Z: point.Z()})
+		if !ok {
+			continue
+		}
+
+		lightVec := mgl32.Vec3{direction.X, direction.Y, direction.Z}
+		dif, spec, ok := model.Reflect(normal, lightVec, watchVec, float32(mat.Shininess))
+		if !ok {
+			continue
+		}
+
+		intensity := light.Intensity * factor
+		color[0] += intensity * light.Color.R * (mat.Diffuse.R*dif + mat.Specular.R*spec)
+		color[1] += intensity * light.Color.G * (mat.Diffuse.G*dif + mat.Specular.G*spec)
+		color[2] += intensity * light.Color.B * (mat.Diffuse.B*dif + mat.Specular.B*spec)
+	}
+
+	for k := range color {
+		if color[k] > 1 {
+			color[k] = 1
+		}
+	}
+
+	return camera.OutputColor(color)
+}
+
+// EntityColor returns the unlit color of an entity used for wireframes.
+func EntityColor(entity *World.Entity, materials WorldMaterials) []float32 {
+	mat := EntityMaterial(entity, materials)
+	color := []float32{mat.Ambient.R + mat.Diffuse.R, mat.Ambient.G + mat.Diffuse.G, mat.Ambient.B + mat.Diffuse.B}
+	for k := range color {
+		if color[k] > 1 {
+			color[k] = 1
+		}
+	}
+
+	return VertexColors(color, 3)
+}
+
+// VertexColors repeats an RGB color for n vertices.
+func VertexColors(color []float32, n int) []float32 {
+	colors := make([]float32, 0, 3*n)
+	for i := 0; i < n; i++ {
+		colors = append(colors, color...)
+	}
+
+	return colors
+}
+
+// SideSurface returns the center and the outward normal of one side of a
+// square entity, as numbered by SideMarker.
+func SideSurface(entity *World.Entity, side int) (mgl32.Vec3, mgl32.Vec3) {
+	corners := SideMarker[side]
+	center := mgl32.Vec3{}
+	for _, c := range corners {
+		center = center.Add(mgl32.Vec3{entity.Points[c].X, entity.Points[c].Y, entity.Points[c].Z})
+	}
+	center = center.Mul(0.25)
+
+	normal := FaceNormal(entity.Points[corners[0]], entity.Points[corners[1]], entity.Points[corners[2]])
+	outward := center.Sub(mgl32.Vec3{entity.Sphere.Center.X, entity.Sphere.Center.Y, entity.Sphere.Center.Z})
+	if normal.Dot(outward) < 0 {
+		normal = normal.Mul(-1)
+	}
+
+	return center, normal
+}
+
+// SetPhongLights passes the eye position, the scene lights and the shading
+// model to the per-pixel shader, which has to be in use.
+func (camera *Camera) SetPhongLights(lights []World.Light, model ShadingModel) {
+	lights = camera.LightingLights(camera.SceneLights(lights))
+	if len(lights) > PhongMaxLights {
+		lights = lights[:PhongMaxLights]
+	}
+
+	types := []int32{}
+	positions := []float32{}
+	directions := []float32{}
+	colors := []float32{}
+	spots := []float32{}
+	attenuations := []float32{}
+	for _, light := range lights {
+		direction := mgl32.Vec3{light.Direction.X, light.Direction.Y, light.Direction.Z}
+		if direction.Len() > 0 {
+			direction = direction.Normalize()
+		}
+
+		types = append(types, lightTypes[light.Type])
+		positions = append(positions, light.Position.X, light.Position.Y, light.Position.Z)
+		directions = append(directions, direction.X(), direction.Y(), direction.Z())
+		colors = append(colors, light.Color.R*light.Intensity, light.Color.G*light.Intensity, light.Color.B*light.Intensity)
+		spots = append(spots, float32(math.Cos(float64(light.Cutoff)*math.Pi/180)), light.Falloff)
+		attenuations = append(attenuations, light.Attenuation.Constant, light.Attenuation.Linear, light.Attenuation.Quadratic)
+	}
+
+	gamma := int32(0)
+	if camera.GammaCorrect {
+		gamma = 1
+	}
+
+	shadingModel, param := phongShadingModel(model)
+
+	gl.Uniform3f(uniform(camera.PhongProgram, "eye"), camera.X, camera.Y, camera.Z)
+	gl.Uniform1i(uniform(camera.PhongProgram, "shading_model"), shadingModel)
+	gl.Uniform1f(uniform(camera.PhongProgram, "shading_param"), param)
+	gl.Uniform1i(uniform(camera.PhongProgram, "gamma_correct"), gamma)
+	gl.Uniform1i(uniform(camera.PhongProgram, "light_count"), int32(len(lights)))
+	gl.Uniform1iv(uniform(camera.PhongProgram, "light_type"), int32(len(lights)), &types[0])
+	gl.Uniform3fv(uniform(camera.PhongProgram, "light_position"), int32(len(lights)), &positions[0])
+	gl.Uniform3fv(uniform(camera.PhongProgram, "light_direction"), int32(len(lights)), &directions[0])
+	gl.Uniform3fv(uniform(camera.PhongProgram, "light_color"), int32(len(lights)), &colors[0])
+	gl.Uniform2fv(uniform(camera.PhongProgram, "light_spot"), int32(len(lights)), &spots[0])
+	gl.Uniform3fv(uniform(camera.PhongProgram, "light_attenuation"), int32(len(lights)), &attenuations[0])
+}
+
+// SetPhongMaterial passes the material to the per-pixel shader, which has to be
+// in use.
+func (camera *Camera) SetPhongMaterial(mat *MaterialElement) {
+	gl.Uniform3f(uniform(camera.PhongProgram, "ambient"), mat.Ambient.R, mat.Ambient.G, mat.Ambient.B)
+	gl.Uniform3f(uniform(camera.PhongProgram, "diffuse"), mat.Diffuse.R, mat.Diffuse.G, mat.Diffuse.B)
+	gl.Uniform3f(uniform(camera.PhongProgram, "specular"), mat.Specular.R, mat.Specular.G, mat.Specular.B)
+	gl.Uniform1f(uniform(camera.PhongProgram, "shininess"), float32(mat.Shininess))
+}
+
+// phongShadingModel numbers the shading models for the per-pixel shader and
+// returns the roughness of Oren-Nayar or the Fresnel of Cook-Torrance.
+func phongShadingModel(model ShadingModel) (int32, float32) {
+	switch m := model.(type) {
+	case BlinnPhong:
+		return 1, 0
+	case Lambert:
+		return 2, 0
+	case OrenNayar:
+		return 3, m.Roughness
+	case CookTorrance:
+		return 4, m.Fresnel
+	}
+
+	return 0, 0
+}
+
+// lightTypes numbers the light types for the per-pixel shader.
+var lightTypes = map[string]int32{
+	World.LightPoint:       0,
+	World.LightDirectional: 1,
+	World.LightSpot:        2,
+}
+
+func uniform(program uint32, name string) int32 {
+	return gl.GetUniformLocation(program, gl.Str(name+"\x00"))
+}
@@ -0,0 +1,89 @@
+package Camera
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/kanister10l/GoCamera/World"
+)
+
+func TestShadeSurfaceModels(t *testing.T) {
+	camera := NewCameraAt(0, 0, 0, 75, 1)
+	mat := MaterialElement{
+		Material:  "test",
+		Shininess: 10,
+		Diffuse:   Ambient{R: 0.2, G: 0.2, B: 0.2},
+		Specular:  Ambient{R: 0.5, G: 0.5, B: 0.5},
+	}
+	lights := []World.Light{{Type: World.LightDirectional, Direction: World.Origin{Z: 1}, Color: World.Color{R: 1, G: 1, B: 1}, Intensity: 1}}
+	point := mgl32.Vec3{0, 0, 10}
+	normal := mgl32.Vec3{0, 0, -1}
+
+	// head on the highlight adds the whole specular color, the matte models
+	// add none and Oren-Nayar darkens the diffuse term by its A factor
+	tests := []struct {
+		model ShadingModel
+		want  float32
+	}{
+		{Phong{}, 0.7},
+		{BlinnPhong{}, 0.7},
+		{Lambert{}, 0.2},
+		{OrenNayar{Roughness: 0.5}, 0.2 * (1 - 0.5*0.25/0.58)},
+	}
+
+	for _, test := range tests {
+		color := camera.ShadeSurface(point, normal, mat, lights, test.model)
+		if d := color[0] - test.want; d > 1e-4 || d < -1e-4 {
+			t.Errorf("%s: got red %v, want %v", test.model.Name(), color[0], test.want)
+		}
+	}
+}
+
+func TestPhongShadingModel(t *testing.T) {
+	seen := map[int32]string{}
+	for _, model := range ShadingModels {
+		k, _ := phongShadingModel(model)
+		if name, ok := seen[k]; ok {
+			t.Errorf("%s and %s share shader model %d", name, model.Name(), k)
+		}
+		seen[k] = model.Name()
+	}
+}
+
+func TestEntityMaterialLibraries(t *testing.T) {
+	named := func(name string, shininess int) MaterialElement {
+		return MaterialElement{Material: name, Shininess: shininess}
+	}
+	materials := WorldMaterials{
+		Library: Material{named("gold", 1), named("oak", 2)},
+		Libraries: map[string]Material{
+			"a.mtl": {named("gold", 3), named("Material", 4)},
+			"b.mtl": {named("Material", 5)},
+		},
+	}
+
+	tests := []struct {
+		material  string
+		libraries []string
+		shininess int
+	}{
+		{"gold", nil, 1},
+		{"gold", []string{"a.mtl"}, 3},
+		{"Material", []string{"a.mtl"}, 4},
+		{"Material", []string{"b.mtl"}, 5},
+		{"Material", []string{"b.mtl", "a.mtl"}, 5},
+		{"oak", []string{"a.mtl"}, 2},
+		{"gold", []string{"missing.mtl"}, 1},
+	}
+
+	for _, test := range tests {
+		entity := World.Entity{Material: test.material, Libraries: test.libraries}
+		if mat := EntityMaterial(&entity, materials); mat.Material != test.material || mat.Shininess != test.shininess {
+			t.Errorf("%s in %v: got %+v, want shininess %d", test.material, test.libraries, mat, test.shininess)
+		}
+	}
+
+	if shadowed := materials.Shadowed(); len(shadowed) != 1 || shadowed[0] != "gold in a.mtl" {
+		t.Errorf("got shadowed %v", shadowed)
+	}
+}
@@ -0,0 +1,152 @@
+package Camera
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+
+	"github.com/kanister10l/GoCamera/Helpers"
+	"github.com/kanister10l/GoCamera/World"
+	"github.com/pkg/errors"
+)
+
+var (
+	sheetBackground = color.RGBA{R: 24, G: 24, B: 24, A: 255}
+	sheetLabel      = color.RGBA{R: 230, G: 230, B: 230, A: 255}
+)
+
+// Contact sheets draw a sphere of this size and tessellation.
+const (
+	sheetSphereRadius = 10
+	sheetRings        = 48
+	sheetSegments     = 96
+)
+
+// RenderContactSheet draws a sphere once for every material of sp into a grid
+// of columns cells, cell pixels wide, with the material name under each
+// sphere. Only the materials of sp are used: the sphere is a UV sphere of
+// fixed tessellation, the viewer is 40 units in front of it, a single white
+// light shines from above left, shading is Phong with gamma correction and
+// tone mapping is clamp at exposure 0, so that sheets made with different
+// settings and by different versions can be compared.
+func RenderContactSheet(sp *SphereWorld, columns, cell int) *image.RGBA {
+	if columns < 1 {
+		columns = 1
+	}
+	if columns > len(sp.Materials) {
+		columns = len(sp.Materials)
+	}
+	rows := (len(sp.Materials) + columns - 1) / columns
+
+	scale := 1 + cell/200
+	labelHeight := (Helpers.GlyphHeight + 4) * scale
+	img := image.NewRGBA(image.Rect(0, 0, columns*cell, rows*(cell+labelHeight)))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: sheetBackground}, image.Point{}, draw.Src)
+
+	camera := NewCameraAt(sp.XOrigin, sp.YOrigin, sp.ZOrigin-40, 35, 1)
+	camera.GammaCorrect = true
+
+	mesh := NewSphereMesh(World.UVSphere(sp.Origin(), sheetSphereRadius, sheetRings, sheetSegments))
+	sheet := CreateSphereWorld(sp.XOrigin, sp.YOrigin, sp.ZOrigin, sp.Radius, sp.Materials)
+	sheet.Lights = []SphereLight{NewSphereLight(float32(math.Pi)-0.6, float32(math.Pi)*1.25, sp.Radius, sp.Origin(), SphereLightColors[0])}
+	sheet.Model, _ = FindShadingModel("phong")
+	sheet.Tone = ToneMapping{}
+	sheet.Prepared = true
+
+	for k, mat := range sp.Materials {
+		sheet.SelectedMaterial = k
+		x := (k % columns) * cell
+		y := (k / columns) * (cell + labelHeight)
+
+		for _, p := range camera.SpherePolygons(mesh, sheet) {
+			if p.Visible {
+				fillPolygon(img, image.Rect(x, y, x+cell, y+cell), p)
+			}
+		}
+
+		label := mat.Material
+		for Helpers.TextWidth(label, scale) > cell-2*scale && len(label) > 0 {
+			label = string([]rune(label)[:len([]rune(label))-1])
+		}
+		Helpers.DrawText(img, x+(cell-Helpers.TextWidth(label, scale))/2, y+cell+scale, scale, label, sheetLabel)
+	}
+
+	return img
+}
+
+// SaveContactSheet renders the contact sheet of RenderContactSheet into a
+// PNG file.
+func SaveContactSheet(path string, sp *SphereWorld, columns, cell int) error {
+	if len(sp.Materials) == 0 {
+		return errors.New("no materials to render")
+	}
+
+	img := RenderContactSheet(sp, columns, cell)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = png.Encode(file, img)
+	if err != nil {
+		file.Close()
+		return errors.Wrap(err, path)
+	}
+
+	return file.Close()
+}
+
+// fillPolygon fills a sphere polygon in screen coordinates into rect,
+// interpolating the vertex colors like the OpenGL drawing does.
+func fillPolygon(img *image.RGBA, rect image.Rectangle, p SpherePolygon) {
+	var xs, ys [3]float32
+	w := float32(rect.Dx())
+	h := float32(rect.Dy())
+	for i := 0; i < 3; i++ {
+		xs[i] = float32(rect.Min.X) + (p.Drawer[3*i]+1)/2*w
+		ys[i] = float32(rect.Min.Y) + (1-p.Drawer[3*i+1])/2*h
+	}
+
+	area := (xs[1]-xs[0])*(ys[2]-ys[0]) - (xs[2]-xs[0])*(ys[1]-ys[0])
+	if area == 0 {
+		return
+	}
+
+	bounds := image.Rect(
+		int(math.Floor(float64(min3(xs)))), int(math.Floor(float64(min3(ys)))),
+		int(math.Ceil(float64(max3(xs))))+1, int(math.Ceil(float64(max3(ys))))+1,
+	).Intersect(rect)
+
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			cx := float32(px) + 0.5
+			cy := float32(py) + 0.5
+			// barycentric weights of the pixel center
+			w0 := ((xs[1]-cx)*(ys[2]-cy) - (xs[2]-cx)*(ys[1]-cy)) / area
+			w1 := ((xs[2]-cx)*(ys[0]-cy) - (xs[0]-cx)*(ys[2]-cy)) / area
+			w2 := 1 - w0 - w1
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+
+			c := [3]uint8{}
+			for i := range c {
+				v := w0*p.Color[i] + w1*p.Color[3+i] + w2*p.Color[6+i]
+				c[i] = uint8(math.Round(float64(clampUnit(v)) * 255))
+			}
+			img.SetRGBA(px, py, color.RGBA{R: c[0], G: c[1], B: c[2], A: 255})
+		}
+	}
+}
+
+func min3(v [3]float32) float32 {
+	return float32(math.Min(float64(v[0]), math.Min(float64(v[1]), float64(v[2]))))
+}
+
+func max3(v [3]float32) float32 {
+	return float32(math.Max(float64(v[0]), math.Max(float64(v[1]), float64(v[2]))))
+}
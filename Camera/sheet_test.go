@@ -0,0 +1,29 @@
+package Camera
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestContactSheetIgnoresSettings(t *testing.T) {
+	sp := CreateSphereWorld(0, 0, 20, 30, DefaultMaterials()[:3])
+	want := RenderContactSheet(sp, 2, 40)
+
+	if b := want.Bounds(); b.Dx() != 80 || b.Dy() <= 80 {
+		t.Errorf("got bounds %v for 2x2 cells of 40 pixels", b)
+	}
+
+	sp.Model = 2
+	sp.Tone = ToneMapping{Operator: 1, Exposure: 2}
+	sp.AddLight()
+	sp.SelectedMaterial = 1
+	got := RenderContactSheet(sp, 2, 40)
+	if !bytes.Equal(got.Pix, want.Pix) {
+		t.Error("the sheet depends on the model, tone or lights of the sphere")
+	}
+
+	sp.Materials[0].Diffuse.R = 1 - sp.Materials[0].Diffuse.R
+	if bytes.Equal(RenderContactSheet(sp, 2, 40).Pix, want.Pix) {
+		t.Error("the sheet does not depend on the materials")
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/gerow/go-color"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/kanister10l/GoCamera/Helpers"
+	"github.com/kanister10l/GoCamera/World"
 )
 
 type SpherePoint struct {
@@ -14,20 +16,20 @@ type SpherePoint struct {
 	Z                 float32
 	Nvector           mgl32.Vec3
 	Intensity         float32
+	SpecularIntensity float32
 	Layer             int
 	MaterialIntensity []float32
+	Dist              float32
+	Visible           bool
 }
 
 type SphereWorld struct {
-	X                float32
-	Y                float32
-	Z                float32
 	XOrigin          float32
 	YOrigin          float32
 	ZOrigin          float32
-	AngleH           float32
-	AngleV           float32
 	Radius           float32
+	Lights           []SphereLight
+	SelectedLight    int
 	Ka               float32
 	Kd               float32
 	Ks               float32
@@ -36,11 +38,33 @@ type SphereWorld struct {
 	Prepared         bool
 	Materials        Material
 	SelectedMaterial int
+	Model            int
+	Compare          bool
+	Tone             ToneMapping
+
+	dirty    bool
+	cacheKey sphereCacheKey
+	vertices Helpers.VertexArray
+}
+
+// sphereCacheKey is what the drawn sphere depends on besides the sphere world.
+type sphereCacheKey struct {
+	mesh  *SphereMesh
+	view  Bookmark
+	gamma bool
+}
+
+// SphereMesh is the geometry drawn in sphere mode, triangles of points.
+type SphereMesh struct {
+	Points []SpherePoint
+	Faces  []World.Face
 }
 
 type SpherePolygon struct {
-	Drawer []float32
-	Color  []float32
+	Drawer  []float32
+	Color   []float32
+	Dist    float32
+	Visible bool
 }
 
 func CreateSphereWorld(xOrigin, yOrigin, zOrigin, dist float32, mat Material) *SphereWorld {
@@ -49,8 +73,8 @@ func CreateSphereWorld(xOrigin, yOrigin, zOrigin, dist float32, mat Material) *S
 	sp.YOrigin = yOrigin
 	sp.ZOrigin = zOrigin
 	sp.Radius = dist
-	sp.AngleH = float32(math.Pi)
-	sp.AngleV = 0
+	sp.Lights = []SphereLight{NewSphereLight(float32(math.Pi), 0, dist, sp.Origin(), SphereLightColors[0])}
+	sp.SelectedLight = 0
 	sp.Hue = 0.5
 	sp.Ka = 0
 	sp.Kd = 0.5
@@ -59,27 +83,31 @@ func CreateSphereWorld(xOrigin, yOrigin, zOrigin, dist float32, mat Material) *S
 	sp.Prepared = false
 	sp.SelectedMaterial = 0
 	sp.Materials = mat
-	sp.Update()
+	sp.dirty = true
 	return sp
 }
 
 func (s *SphereWorld) Rotate(horizontalDelta float32, verticalDelta float32) {
-	s.AngleH += horizontalDelta
-	s.AngleV += verticalDelta
-
-	if s.AngleH < 0 {
-		s.AngleH = float32(2*math.Pi) + s.AngleH
-	} else if s.AngleH > float32(2*math.Pi) {
-		s.AngleH = s.AngleH - float32(2*math.Pi)
+	if light := s.SelectedSphereLight(); light != nil {
+		light.Rotate(horizontalDelta, verticalDelta)
+		s.Invalidate()
 	}
+}
 
-	if s.AngleV < 0 {
-		s.AngleV = float32(2*math.Pi) + s.AngleV
-	} else if s.AngleV > float32(2*math.Pi) {
-		s.AngleV = s.AngleV - float32(2*math.Pi)
-	}
+// Invalidate makes the next DrawSphere light the sphere again.
+func (s *SphereWorld) Invalidate() {
+	s.dirty = true
+}
 
-	s.Update()
+// Restore sets the parameters of a copy taken earlier, keeping the material
+// library, which can be reloaded in the meantime, and the drawing buffers.
+func (s *SphereWorld) Restore(state SphereWorld) {
+	materials := s.Materials
+	vertices := s.vertices
+	*s = state
+	s.Lights = append([]SphereLight{}, state.Lights...)
+	s.vertices = vertices
+	s.SetMaterials(materials)
 }
 
 func (sp *SphereWorld) ModifyConstant(a, d, s, h float32, n int) {
@@ -118,6 +146,7 @@ func (sp *SphereWorld) ModifyConstant(a, d, s, h float32, n int) {
 	}
 
 	sp.Prepared = false
+	sp.Invalidate()
 
 	log.Printf(`
 	Ka ---> %f
@@ -127,13 +156,28 @@ func (sp *SphereWorld) ModifyConstant(a, d, s, h float32, n int) {
 	`, sp.Ka, sp.Kd, sp.Ks, sp.N)
 }
 
-func (s *SphereWorld) Update() {
-	s.X = s.Radius * float32(math.Sin(float64(s.AngleH))) * float32(math.Cos(float64(s.AngleV)))
-	s.Y = s.Radius * float32(math.Sin(float64(s.AngleH))) * float32(math.Sin(float64(s.AngleV)))
-	s.Z = s.Radius * float32(math.Cos(float64(s.AngleH)))
+func (s *SphereWorld) NextShadingModel() {
+	s.Model = (s.Model + 1) % len(ShadingModels)
+	s.Invalidate()
+	log.Println("Current shading model --->", ShadingModels[s.Model].Name())
+}
+
+// ToggleCompare switches between the selected shading model and a row of the
+// sphere drawn with every model, from left to right.
+func (s *SphereWorld) ToggleCompare() {
+	s.Compare = !s.Compare
+	s.Invalidate()
+	if s.Compare {
+		log.Println("Comparing shading models --->", ShadingModelNames())
+	}
 }
 
 func (s *SphereWorld) SelectNextMaterial() {
+	if len(s.Materials) == 0 {
+		log.Println("No materials loaded")
+		return
+	}
+
 	if s.SelectedMaterial == len(s.Materials)-1 {
 		s.SelectedMaterial = 0
 	} else {
@@ -141,11 +185,44 @@ func (s *SphereWorld) SelectNextMaterial() {
 	}
 
 	s.Prepared = true
+	s.Invalidate()
 	log.Println("Current material --->", s.Materials[s.SelectedMaterial].Material)
 
 	return
 }
 
+// SetMaterials replaces the material library keeping the selected index when
+// it is still valid.
+func (s *SphereWorld) SetMaterials(mat Material) {
+	s.Materials = mat
+	if s.SelectedMaterial >= len(s.Materials) {
+		s.SelectedMaterial = 0
+	}
+	s.Invalidate()
+}
+
+// NewSphereMesh makes sphere mode geometry out of a world mesh.
+func NewSphereMesh(mesh World.Mesh) *SphereMesh {
+	points := make([]SpherePoint, len(mesh.Points))
+	for k, p := range mesh.Points {
+		points[k] = SpherePoint{
+			X:                 p.X,
+			Y:                 p.Y,
+			Z:                 p.Z,
+			Nvector:           mgl32.Vec3{mesh.Normals[k].X, mesh.Normals[k].Y, mesh.Normals[k].Z},
+			MaterialIntensity: []float32{0, 0, 0},
+		}
+	}
+
+	return &SphereMesh{Points: points, Faces: mesh.Faces}
+}
+
+// NewDiscMesh makes sphere mode geometry out of the points made by
+// GenerateSphere.
+func NewDiscMesh(points []SpherePoint) *SphereMesh {
+	return &SphereMesh{Points: points, Faces: LayerFaces(points)}
+}
+
 func GenerateSphere(r, xOrigin, yOrigin, zOrigin float32, resolution int, angleResolution int) []SpherePoint {
 	points := []SpherePoint{}
 	gap := r / float32(resolution-1)
@@ -188,191 +265,150 @@ func GenerateSphere(r, xOrigin, yOrigin, zOrigin float32, resolution int, angleR
 	return points
 }
 
-func CalculateLightIntensity(points []SpherePoint, xSource, ySource, zSource, xWatch, yWatch, zWatch, ka, kd, ks float32, n int) []SpherePoint {
-	intensity := float32(0.0)
-	max := float32(0.0)
-	for k := range points {
-		lightVec := mgl32.NewVecNFromData([]float32{points[k].X - xSource, points[k].Y - ySource, points[k].Z - zSource}).Vec3().Normalize()
-		watchVec := mgl32.NewVecNFromData([]float32{xWatch - points[k].X, yWatch - points[k].Y, zWatch - points[k].Z}).Vec3().Normalize()
-
-		if points[k].Nvector.Dot(watchVec) >= 0 && points[k].Nvector.Dot(lightVec.Mul(-1)) >= 0 {
-			reflectionVec := lightVec.Sub(points[k].Nvector.Mul(lightVec.Dot(points[k].Nvector) * 2))
-			ref := float64(reflectionVec.Dot(watchVec))
-			dif := points[k].Nvector.Dot(lightVec.Mul(-1))
-			if ref < 0 {
-				ref = 0
-			}
-			if dif < 0 {
-				dif = 0
+// CalculateLightIntensity sets the linear intensity of every point, which
+// exceeds 1 under bright lights and is tone mapped by Polygonyfy, and the
+// specular part of it.
+func CalculateLightIntensity(points []SpherePoint, lights []SphereLight, model ShadingModel, xWatch, yWatch, zWatch, ka, kd, ks float32, n int) []SpherePoint {
+	parallelFor(len(points), func(start, end int) {
+		intensity := float32(0.0)
+		specular := float32(0.0)
+		for k := start; k < end; k++ {
+			watchVec := mgl32.NewVecNFromData([]float32{xWatch - points[k].X, yWatch - points[k].Y, zWatch - points[k].Z}).Vec3().Normalize()
+
+			intensity = 0
+			specular = 0
+			lit := false
+			for _, light := range lights {
+				if !light.Enabled {
+					continue
+				}
+
+				direction, factor, ok := light.Illuminate(World.Origin{X: points[k].X, Y: points[k].Y, Z: points[k].Z})
+				if !ok {
+					continue
+				}
+
+				lightVec := mgl32.Vec3{direction.X, direction.Y, direction.Z}
+				if dif, spec, ok := model.Reflect(points[k].Nvector, lightVec, watchVec, float32(n)); ok {
+					intensity += factor * light.Luminance() * (kd*dif + ks*spec)
+					specular += factor * light.Luminance() * ks * spec
+					lit = true
+				}
 			}
 
-			intensity = ka + kd*dif + ks*float32(math.Pow(ref, float64(n)))
-			points[k].Intensity = intensity
-			if intensity > max {
-				max = intensity
+			if lit {
+				points[k].Intensity = intensity + ka
+				points[k].SpecularIntensity = specular
+			} else {
+				points[k].Intensity = 0
+				points[k].SpecularIntensity = 0
 			}
-		} else {
-			points[k].Intensity = 0
 		}
-	}
-
-	if max > 1 {
-		for k := range points {
-			points[k].Intensity /= max
-		}
-	}
+	})
 
 	return points
 }
 
-func CalculateMaterialIntensity(points []SpherePoint, xSource, ySource, zSource, xWatch, yWatch, zWatch float32, mat MaterialElement) []SpherePoint {
-	rIntensity := float32(0.0)
-	gIntensity := float32(0.0)
-	bIntensity := float32(0.0)
-	max := float32(0.0)
-	for k := range points {
-		lightVec := mgl32.NewVecNFromData([]float32{points[k].X - xSource, points[k].Y - ySource, points[k].Z - zSource}).Vec3().Normalize()
-		watchVec := mgl32.NewVecNFromData([]float32{xWatch - points[k].X, yWatch - points[k].Y, zWatch - points[k].Z}).Vec3().Normalize()
-
-		if points[k].Nvector.Dot(watchVec) >= 0 && points[k].Nvector.Dot(lightVec.Mul(-1)) >= 0 {
-			reflectionVec := lightVec.Sub(points[k].Nvector.Mul(lightVec.Dot(points[k].Nvector) * 2))
-			ref := float64(reflectionVec.Dot(watchVec))
-			dif := points[k].Nvector.Dot(lightVec.Mul(-1))
-			if ref < 0 {
-				ref = 0
-			}
-			if dif < 0 {
-				dif = 0
+// CalculateMaterialIntensity sets the linear RGB intensity of every point,
+// which is tone mapped by PolygonyfyMaterial.
+func CalculateMaterialIntensity(points []SpherePoint, lights []SphereLight, model ShadingModel, xWatch, yWatch, zWatch float32, mat MaterialElement) []SpherePoint {
+	parallelFor(len(points), func(start, end int) {
+		rIntensity := float32(0.0)
+		gIntensity := float32(0.0)
+		bIntensity := float32(0.0)
+		for k := start; k < end; k++ {
+			watchVec := mgl32.NewVecNFromData([]float32{xWatch - points[k].X, yWatch - points[k].Y, zWatch - points[k].Z}).Vec3().Normalize()
+
+			rIntensity, gIntensity, bIntensity = 0, 0, 0
+			lit := false
+			for _, light := range lights {
+				if !light.Enabled {
+					continue
+				}
+
+				direction, factor, ok := light.Illuminate(World.Origin{X: points[k].X, Y: points[k].Y, Z: points[k].Z})
+				if !ok {
+					continue
+				}
+
+				lightVec := mgl32.Vec3{direction.X, direction.Y, direction.Z}
+				if dif, spec, ok := model.Reflect(points[k].Nvector, lightVec, watchVec, float32(mat.Shininess)); ok {
+					rIntensity += factor * light.Intensity * light.Color.R * (mat.Diffuse.R*dif + mat.Specular.R*spec)
+					gIntensity += factor * light.Intensity * light.Color.G * (mat.Diffuse.G*dif + mat.Specular.G*spec)
+					bIntensity += factor * light.Intensity * light.Color.B * (mat.Diffuse.B*dif + mat.Specular.B*spec)
+					lit = true
+				}
 			}
 
-			rIntensity = mat.Ambient.R + mat.Diffuse.R*dif + mat.Specular.R*float32(math.Pow(ref, float64(mat.Shininess)))
-			gIntensity = mat.Ambient.G + mat.Diffuse.G*dif + mat.Specular.G*float32(math.Pow(ref, float64(mat.Shininess)))
-			bIntensity = mat.Ambient.B + mat.Diffuse.B*dif + mat.Specular.B*float32(math.Pow(ref, float64(mat.Shininess)))
+			if lit {
+				rIntensity += mat.Ambient.R
+				gIntensity += mat.Ambient.G
+				bIntensity += mat.Ambient.B
 
-			points[k].MaterialIntensity = []float32{rIntensity, gIntensity, bIntensity}
-			if rIntensity > max {
-				max = rIntensity
-			}
-			if gIntensity > max {
-				max = gIntensity
-			}
-			if bIntensity > max {
-				max = bIntensity
+				points[k].MaterialIntensity = []float32{rIntensity, gIntensity, bIntensity}
+			} else {
+				points[k].MaterialIntensity = []float32{0, 0, 0}
 			}
-		} else {
-			points[k].MaterialIntensity = []float32{0, 0, 0}
-		}
-	}
-
-	if max > 1 {
-		for k := range points {
-			points[k].MaterialIntensity[0] /= max
-			points[k].MaterialIntensity[1] /= max
-			points[k].MaterialIntensity[2] /= max
 		}
-	}
+	})
 
 	return points
 }
 
-func Polygonyfy(points []SpherePoint, xCanvasSize, yCanvasSize, hue float32) []SpherePolygon {
-	poly := []SpherePolygon{}
-	hsl := color.HSL{H: float64(hue), S: 1, L: 0}
-
-	//calc layer capacity
-	lc := 0
-	for _, point := range points {
-		if point.Layer == 1 {
-			lc++
-		} else if point.Layer > 1 {
-			break
-		}
-	}
-
-	for i := 0; i < lc; i++ {
-		xp := i + 2
-		if i == lc-1 {
-			xp = 1
+// Polygonyfy colors the points with the hue at their intensity. With gamma
+// correction the hue is decoded from sRGB and lit in linear light, leaving
+// highlights white, otherwise the intensity is used as the HSL lightness.
+func Polygonyfy(mesh *SphereMesh, points []SpherePoint, xCanvasSize, yCanvasSize, hue float32, tone ToneMapping, gamma bool) []SpherePolygon {
+	base := color.HSL{H: float64(hue), S: 1, L: 0.5}.ToRGB()
+	linear := []float32{SRGBToLinear(float32(base.R)), SRGBToLinear(float32(base.G)), SRGBToLinear(float32(base.B))}
+
+	return polygonyfy(mesh, points, xCanvasSize, yCanvasSize, func(point SpherePoint) []float32 {
+		if gamma {
+			diffuse := point.Intensity - point.SpecularIntensity
+			c := tone.MapColor([]float32{
+				linear[0]*diffuse + point.SpecularIntensity,
+				linear[1]*diffuse + point.SpecularIntensity,
+				linear[2]*diffuse + point.SpecularIntensity,
+			})
+			return []float32{LinearToSRGB(c[0]), LinearToSRGB(c[1]), LinearToSRGB(c[2])}
 		}
 
-		hsl.L = float64(points[0].Intensity)
-		p1L := hsl.ToRGB()
-		hsl.L = float64(points[i+1].Intensity)
-		p2L := hsl.ToRGB()
-		hsl.L = float64(points[xp].Intensity)
-		p3L := hsl.ToRGB()
-		poly = append(poly, SpherePolygon{
-			Drawer: []float32{
-				points[0].X / xCanvasSize, points[0].Y / yCanvasSize, 0,
-				points[i+1].X / xCanvasSize, points[i+1].Y / yCanvasSize, 0,
-				points[xp].X / xCanvasSize, points[xp].Y / yCanvasSize, 0,
-			},
-			Color: []float32{
-				float32(p1L.R), float32(p1L.G), float32(p1L.B),
-				float32(p2L.R), float32(p2L.G), float32(p2L.B),
-				float32(p3L.R), float32(p3L.G), float32(p3L.B),
-			},
-		})
-	}
-
-	for i := 1; i < len(points)-lc; i += lc {
-		for j := 0; j < lc; j++ {
-			xp := j + 1
-			xm := j - 1
-			if j == 0 {
-				xm = lc - 1
-			}
-			if j == lc-1 {
-				xp = 0
-			}
+		hsl := color.HSL{H: float64(hue), S: 1, L: float64(tone.Map(point.Intensity))}
+		rgb := hsl.ToRGB()
+		return []float32{float32(rgb.R), float32(rgb.G), float32(rgb.B)}
+	})
+}
 
-			//Polygon 1
-			hsl.L = float64(points[i+j].Intensity)
-			p1L := hsl.ToRGB()
-			hsl.L = float64(points[i+j+lc].Intensity)
-			p2L := hsl.ToRGB()
-			hsl.L = float64(points[i+xp+lc].Intensity)
-			p3L := hsl.ToRGB()
-			poly = append(poly, SpherePolygon{
-				Drawer: []float32{
-					points[i+j].X / xCanvasSize, points[i+j].Y / yCanvasSize, 0,
-					points[i+j+lc].X / xCanvasSize, points[i+j+lc].Y / yCanvasSize, 0,
-					points[i+xp+lc].X / xCanvasSize, points[i+xp+lc].Y / yCanvasSize, 0,
-				},
-				Color: []float32{
-					float32(p1L.R), float32(p1L.G), float32(p1L.B),
-					float32(p2L.R), float32(p2L.G), float32(p2L.B),
-					float32(p3L.R), float32(p3L.G), float32(p3L.B),
-				},
-			})
+// PolygonyfyMaterial colors the points with their tone mapped material
+// intensity, encoded as sRGB with gamma correction.
+func PolygonyfyMaterial(mesh *SphereMesh, points []SpherePoint, xCanvasSize, yCanvasSize float32, tone ToneMapping, gamma bool) []SpherePolygon {
+	return polygonyfy(mesh, points, xCanvasSize, yCanvasSize, func(point SpherePoint) []float32 {
+		c := tone.MapColor(point.MaterialIntensity)
+		if gamma {
+			return []float32{LinearToSRGB(c[0]), LinearToSRGB(c[1]), LinearToSRGB(c[2])}
+		}
+		return c
+	})
+}
 
-			//Polygon 2
-			hsl.L = float64(points[i+j].Intensity)
-			p1L = hsl.ToRGB()
-			hsl.L = float64(points[i+j+lc].Intensity)
-			p2L = hsl.ToRGB()
-			hsl.L = float64(points[i+xm].Intensity)
-			p3L = hsl.ToRGB()
-			poly = append(poly, SpherePolygon{
-				Drawer: []float32{
-					points[i+j].X / xCanvasSize, points[i+j].Y / yCanvasSize, 0,
-					points[i+j+lc].X / xCanvasSize, points[i+j+lc].Y / yCanvasSize, 0,
-					points[i+xm].X / xCanvasSize, points[i+xm].Y / yCanvasSize, 0,
-				},
-				Color: []float32{
-					float32(p1L.R), float32(p1L.G), float32(p1L.B),
-					float32(p2L.R), float32(p2L.G), float32(p2L.B),
-					float32(p3L.R), float32(p3L.G), float32(p3L.B),
-				},
-			})
+// polygonyfy makes a polygon of every mesh face out of points, which may be
+// the mesh points projected on the screen. color is called concurrently.
+func polygonyfy(mesh *SphereMesh, points []SpherePoint, xCanvasSize, yCanvasSize float32, color func(SpherePoint) []float32) []SpherePolygon {
+	poly := make([]SpherePolygon, len(mesh.Faces))
+	parallelFor(len(mesh.Faces), func(start, end int) {
+		for k := start; k < end; k++ {
+			f := mesh.Faces[k]
+			poly[k] = spherePolygon(points, f.P1, f.P2, f.P3, xCanvasSize, yCanvasSize, color)
 		}
-	}
+	})
 
 	return poly
 }
 
-func PolygonyfyMaterial(points []SpherePoint, xCanvasSize, yCanvasSize float32) []SpherePolygon {
-	poly := []SpherePolygon{}
+// LayerFaces returns the triangles of the points made by GenerateSphere, a
+// disc of rings around the first point, every ring being a Layer.
+func LayerFaces(points []SpherePoint) []World.Face {
+	faces := []World.Face{}
 
 	//calc layer capacity
 	lc := 0
@@ -390,18 +426,7 @@ func PolygonyfyMaterial(points []SpherePoint, xCanvasSize, yCanvasSize float32)
 			xp = 1
 		}
 
-		poly = append(poly, SpherePolygon{
-			Drawer: []float32{
-				points[0].X / xCanvasSize, points[0].Y / yCanvasSize, 0,
-				points[i+1].X / xCanvasSize, points[i+1].Y / yCanvasSize, 0,
-				points[xp].X / xCanvasSize, points[xp].Y / yCanvasSize, 0,
-			},
-			Color: []float32{
-				points[0].MaterialIntensity[0], points[0].MaterialIntensity[1], points[0].MaterialIntensity[2],
-				points[i+1].MaterialIntensity[0], points[i+1].MaterialIntensity[1], points[i+1].MaterialIntensity[2],
-				points[xp].MaterialIntensity[0], points[xp].MaterialIntensity[1], points[xp].MaterialIntensity[2],
-			},
-		})
+		faces = append(faces, World.Face{P1: 0, P2: i + 1, P3: xp})
 	}
 
 	for i := 1; i < len(points)-lc; i += lc {
@@ -416,34 +441,24 @@ func PolygonyfyMaterial(points []SpherePoint, xCanvasSize, yCanvasSize float32)
 			}
 
 			//Polygon 1
-			poly = append(poly, SpherePolygon{
-				Drawer: []float32{
-					points[i+j].X / xCanvasSize, points[i+j].Y / yCanvasSize, 0,
-					points[i+j+lc].X / xCanvasSize, points[i+j+lc].Y / yCanvasSize, 0,
-					points[i+xp+lc].X / xCanvasSize, points[i+xp+lc].Y / yCanvasSize, 0,
-				},
-				Color: []float32{
-					points[i+j].MaterialIntensity[0], points[i+j].MaterialIntensity[1], points[i+j].MaterialIntensity[2],
-					points[i+j+lc].MaterialIntensity[0], points[i+j+lc].MaterialIntensity[1], points[i+j+lc].MaterialIntensity[2],
-					points[i+xp+lc].MaterialIntensity[0], points[i+xp+lc].MaterialIntensity[1], points[i+xp+lc].MaterialIntensity[2],
-				},
-			})
+			faces = append(faces, World.Face{P1: i + j, P2: i + j + lc, P3: i + xp + lc})
 
 			//Polygon 2
-			poly = append(poly, SpherePolygon{
-				Drawer: []float32{
-					points[i+j].X / xCanvasSize, points[i+j].Y / yCanvasSize, 0,
-					points[i+j+lc].X / xCanvasSize, points[i+j+lc].Y / yCanvasSize, 0,
-					points[i+xm].X / xCanvasSize, points[i+xm].Y / yCanvasSize, 0,
-				},
-				Color: []float32{
-					points[i+j].MaterialIntensity[0], points[i+j].MaterialIntensity[1], points[i+j].MaterialIntensity[2],
-					points[i+j+lc].MaterialIntensity[0], points[i+j+lc].MaterialIntensity[1], points[i+j+lc].MaterialIntensity[2],
-					points[i+xm].MaterialIntensity[0], points[i+xm].MaterialIntensity[1], points[i+xm].MaterialIntensity[2],
-				},
-			})
+			faces = append(faces, World.Face{P1: i + j, P2: i + j + lc, P3: i + xm})
 		}
 	}
 
-	return poly
+	return faces
+}
+
+func spherePolygon(points []SpherePoint, a, b, c int, xCanvasSize, yCanvasSize float32, color func(SpherePoint) []float32) SpherePolygon {
+	polygon := SpherePolygon{}
+	for _, k := range []int{a, b, c} {
+		polygon.Drawer = append(polygon.Drawer, points[k].X/xCanvasSize, points[k].Y/yCanvasSize, 0)
+		polygon.Color = append(polygon.Color, color(points[k])...)
+		polygon.Dist += points[k].Dist / 3
+		polygon.Visible = polygon.Visible || points[k].Visible
+	}
+
+	return polygon
 }
@@ -0,0 +1,211 @@
+package Camera
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"math"
+
+	"github.com/kanister10l/GoCamera/World"
+	"github.com/pkg/errors"
+)
+
+// SphereLight orbits the origin at Radius. Directional and spot lights always
+// aim at Target, the center of the sphere.
+type SphereLight struct {
+	World.Light
+	AngleH  float32
+	AngleV  float32
+	Radius  float32
+	Target  World.Origin
+	Enabled bool
+}
+
+var SphereLightColors = []World.Color{
+	{R: 1, G: 1, B: 1},
+	{R: 1, G: 0.3, B: 0.3},
+	{R: 0.3, G: 1, B: 0.3},
+	{R: 0.3, G: 0.3, B: 1},
+	{R: 1, G: 1, B: 0.3},
+}
+
+func NewSphereLight(angleH, angleV, radius float32, target World.Origin, color World.Color) SphereLight {
+	light := SphereLight{
+		Light:   World.Light{Type: World.LightPoint, Color: color, Intensity: 1},
+		AngleH:  angleH,
+		AngleV:  angleV,
+		Radius:  radius,
+		Target:  target,
+		Enabled: true,
+	}
+	light.Update()
+	return light
+}
+
+// LoadSphereLights reads a JSON list of sphere lights. Missing angles put the
+// light in front of the sphere, a missing radius uses the given one and the
+// rest defaults like World.DefaultLight.
+func LoadSphereLights(path string, radius float32, target World.Origin) ([]SphereLight, error) {
+	buffer, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	data := []json.RawMessage{}
+	err = json.Unmarshal(buffer, &data)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	lights := []SphereLight{}
+	for k, raw := range data {
+		light := SphereLight{Light: World.DefaultLight(), AngleH: float32(math.Pi), Radius: radius, Target: target, Enabled: true}
+		err = json.Unmarshal(raw, &light)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: light %d", path, k)
+		}
+
+		err = light.SetDefaults()
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: light %d", path, k)
+		}
+		light.Update()
+		lights = append(lights, light)
+	}
+
+	return lights, nil
+}
+
+func (l *SphereLight) Rotate(horizontalDelta float32, verticalDelta float32) {
+	l.AngleH = wrapAngle(l.AngleH + horizontalDelta)
+	l.AngleV = wrapAngle(l.AngleV + verticalDelta)
+	l.Update()
+}
+
+func (l *SphereLight) Update() {
+	l.Position.X = l.Radius * float32(math.Sin(float64(l.AngleH))) * float32(math.Cos(float64(l.AngleV)))
+	l.Position.Y = l.Radius * float32(math.Sin(float64(l.AngleH))) * float32(math.Sin(float64(l.AngleV)))
+	l.Position.Z = l.Radius * float32(math.Cos(float64(l.AngleH)))
+	if l.Type != World.LightPoint {
+		l.Direction = World.Origin{X: l.Target.X - l.Position.X, Y: l.Target.Y - l.Position.Y, Z: l.Target.Z - l.Position.Z}
+	}
+}
+
+// Luminance is used by the single hue mode, which can not show the light color.
+func (l SphereLight) Luminance() float32 {
+	return l.Intensity * (l.Color.R + l.Color.G + l.Color.B) / 3
+}
+
+func wrapAngle(angle float32) float32 {
+	if angle < 0 {
+		return float32(2*math.Pi) + angle
+	} else if angle > float32(2*math.Pi) {
+		return angle - float32(2*math.Pi)
+	}
+	return angle
+}
+
+func (s *SphereWorld) Origin() World.Origin {
+	return World.Origin{X: s.XOrigin, Y: s.YOrigin, Z: s.ZOrigin}
+}
+
+func (s *SphereWorld) SelectedSphereLight() *SphereLight {
+	if s.SelectedLight < 0 || s.SelectedLight >= len(s.Lights) {
+		return nil
+	}
+	return &s.Lights[s.SelectedLight]
+}
+
+// AddLight places a new light a quarter turn away from the selected one and
+// selects it.
+func (s *SphereWorld) AddLight() {
+	angleH := float32(math.Pi)
+	angleV := float32(0)
+	if light := s.SelectedSphereLight(); light != nil {
+		angleH = wrapAngle(light.AngleH + math.Pi/2)
+		angleV = light.AngleV
+	}
+
+	color := SphereLightColors[len(s.Lights)%len(SphereLightColors)]
+	s.Lights = append(s.Lights, NewSphereLight(angleH, angleV, s.Radius, s.Origin(), color))
+	s.SelectedLight = len(s.Lights) - 1
+	s.Invalidate()
+	s.logLights()
+}
+
+func (s *SphereWorld) RemoveLight() {
+	if s.SelectedSphereLight() == nil {
+		log.Println("No light to remove")
+		return
+	}
+
+	s.Lights = append(s.Lights[:s.SelectedLight:s.SelectedLight], s.Lights[s.SelectedLight+1:]...)
+	if s.SelectedLight >= len(s.Lights) {
+		s.SelectedLight = len(s.Lights) - 1
+	}
+	if s.SelectedLight < 0 {
+		s.SelectedLight = 0
+	}
+	s.Invalidate()
+	s.logLights()
+}
+
+func (s *SphereWorld) NextLight() {
+	if len(s.Lights) == 0 {
+		log.Println("No light to select")
+		return
+	}
+
+	s.SelectedLight = (s.SelectedLight + 1) % len(s.Lights)
+	s.logLights()
+}
+
+func (s *SphereWorld) ToggleLight() {
+	if light := s.SelectedSphereLight(); light != nil {
+		light.Enabled = !light.Enabled
+		s.Invalidate()
+		s.logLights()
+	}
+}
+
+// NextLightType switches the selected light between point, directional and spot.
+func (s *SphereWorld) NextLightType() {
+	light := s.SelectedSphereLight()
+	if light == nil {
+		return
+	}
+
+	if light.Type == World.LightPoint {
+		light.Type = World.LightDirectional
+	} else if light.Type == World.LightDirectional {
+		light.Type = World.LightSpot
+	} else {
+		light.Type = World.LightPoint
+	}
+	light.SetDefaults()
+	light.Update()
+	s.Invalidate()
+	s.logLights()
+}
+
+func (s *SphereWorld) ModifyLightIntensity(delta float32) {
+	if light := s.SelectedSphereLight(); light != nil {
+		light.Intensity += delta
+		if light.Intensity < 0 {
+			light.Intensity = 0
+		}
+		s.Invalidate()
+		s.logLights()
+	}
+}
+
+func (s *SphereWorld) logLights() {
+	for k, light := range s.Lights {
+		selected := " "
+		if k == s.SelectedLight {
+			selected = ">"
+		}
+		log.Printf("%s light %d ---> %s, color (%.2f, %.2f, %.2f), intensity %.2f, enabled %t",
+			selected, k, light.Type, light.Color.R, light.Color.G, light.Color.B, light.Intensity, light.Enabled)
+	}
+}
@@ -0,0 +1,169 @@
+package Camera
+
+import (
+	"log"
+	"math"
+
+	"github.com/kanister10l/GoCamera/World"
+)
+
+const (
+	MinSphereRings        = 3
+	MaxSphereRings        = 200
+	MaxSphereSubdivisions = 6
+)
+
+var SphereKinds = []string{"uv", "ico", "disc"}
+
+func IsSphereKind(kind string) bool {
+	for _, v := range SphereKinds {
+		if v == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SphereTessellation makes the mesh of sphere mode and makes it again when
+// the resolution changes. Kind is "uv", "ico" or "disc", the latter being the
+// front facing disc of GenerateSphere with Rings layers. With Auto the
+// resolution follows the size of the sphere on the screen.
+type SphereTessellation struct {
+	Kind         string
+	Center       World.Origin
+	Radius       float32
+	Rings        int
+	Segments     int
+	Subdivisions int
+	Auto         bool
+
+	mesh *SphereMesh
+}
+
+func NewSphereTessellation(kind string, center World.Origin, radius float32, rings, segments, subdivisions int) *SphereTessellation {
+	return &SphereTessellation{
+		Kind:         kind,
+		Center:       center,
+		Radius:       radius,
+		Rings:        rings,
+		Segments:     segments,
+		Subdivisions: subdivisions,
+	}
+}
+
+func (t *SphereTessellation) Mesh() *SphereMesh {
+	if t.mesh != nil {
+		return t.mesh
+	}
+
+	if t.Kind == "disc" {
+		t.mesh = NewDiscMesh(GenerateSphere(t.Radius, t.Center.X, t.Center.Y, t.Center.Z, t.Rings, t.Segments))
+	} else {
+		t.mesh = NewSphereMesh(World.Sphere{
+			Center:       t.Center,
+			Radius:       t.Radius,
+			Kind:         t.Kind,
+			Rings:        t.Rings,
+			Segments:     t.Segments,
+			Subdivisions: t.Subdivisions,
+		}.Mesh())
+	}
+
+	log.Printf("Sphere tessellation ---> %s, %d rings, %d segments, %d subdivisions, %d faces",
+		t.Kind, t.Rings, t.Segments, t.Subdivisions, len(t.mesh.Faces))
+	return t.mesh
+}
+
+// Refine raises the resolution by steps, or lowers it for negative steps,
+// each step being a quarter more rings and segments or one subdivision. It
+// turns the automatic level of detail off.
+func (t *SphereTessellation) Refine(steps int) {
+	if t.Auto {
+		t.ToggleAuto()
+	}
+	scale := math.Pow(1.25, float64(steps))
+	t.SetResolution(int(math.Round(float64(t.Rings)*scale)), int(math.Round(float64(t.Segments)*scale)), t.Subdivisions+steps)
+}
+
+func (t *SphereTessellation) SetResolution(rings, segments, subdivisions int) {
+	rings = clampInt(rings, MinSphereRings, MaxSphereRings)
+	segments = clampInt(segments, MinSphereRings, 2*MaxSphereRings)
+	subdivisions = clampInt(subdivisions, 0, MaxSphereSubdivisions)
+	if rings == t.Rings && segments == t.Segments && subdivisions == t.Subdivisions {
+		return
+	}
+
+	t.Rings = rings
+	t.Segments = segments
+	t.Subdivisions = subdivisions
+	t.mesh = nil
+}
+
+func (t *SphereTessellation) ToggleAuto() {
+	t.Auto = !t.Auto
+	log.Println("Automatic sphere level of detail --->", t.Auto)
+}
+
+// UpdateLevelOfDetail picks the resolution from the share of the screen
+// height the sphere takes, MaxSphereRings filling the whole screen. Changes of
+// less than a fifth of the rings are ignored, so that moving the camera does
+// not make the mesh again every frame.
+func (t *SphereTessellation) UpdateLevelOfDetail(camera *Camera) {
+	if !t.Auto {
+		return
+	}
+
+	share := float64(1)
+	distance := distanceTo(camera, t.Center)
+	if distance > t.Radius {
+		angle := 2 * math.Asin(float64(t.Radius/distance)) * 180 / math.Pi
+		share = math.Min(1, angle/float64(camera.VerticalFov))
+	}
+
+	rings := int(math.Round(share * MaxSphereRings))
+	if t.Kind != "disc" {
+		rings /= 2
+	}
+
+	segments := 2 * rings
+	if t.Kind == "disc" {
+		segments = rings * 3 / 5
+	}
+
+	// an icosphere with at most as many faces as the UV sphere
+	subdivisions := 0
+	for 20<<uint(2*(subdivisions+1)) <= 2*rings*segments && subdivisions < MaxSphereSubdivisions {
+		subdivisions++
+	}
+
+	if 5*absInt(rings-t.Rings) < t.Rings && subdivisions == t.Subdivisions {
+		return
+	}
+	t.SetResolution(rings, segments, subdivisions)
+}
+
+func distanceTo(camera *Camera, p World.Origin) float32 {
+	x := p.X - camera.X
+	y := p.Y - camera.Y
+	z := p.Z - camera.Z
+	return float32(math.Sqrt(float64(x*x + y*y + z*z)))
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	} else if v > max {
+		return max
+	}
+
+	return v
+}
@@ -0,0 +1,64 @@
+package Camera
+
+import (
+	"log"
+	"math"
+)
+
+const (
+	ToneClamp = iota
+	ToneReinhard
+	ToneACES
+)
+
+var ToneMappingNames = []string{"clamp", "reinhard", "aces"}
+
+// ToneMapping maps linear intensities, which may exceed 1, into the 0-1 range
+// of the screen after scaling them by 2 to the power of Exposure.
+type ToneMapping struct {
+	Operator int
+	Exposure float32
+}
+
+func (t ToneMapping) Map(v float32) float32 {
+	x := float64(v) * math.Pow(2, float64(t.Exposure))
+	if x < 0 {
+		x = 0
+	}
+
+	switch t.Operator {
+	case ToneReinhard:
+		x = x / (1 + x)
+	case ToneACES:
+		// Narkowicz's fit of the ACES filmic curve
+		x = x * (2.51*x + 0.03) / (x*(2.43*x+0.59) + 0.14)
+	}
+
+	return float32(math.Min(x, 1))
+}
+
+func (t ToneMapping) MapColor(color []float32) []float32 {
+	return []float32{t.Map(color[0]), t.Map(color[1]), t.Map(color[2])}
+}
+
+func FindToneMapping(name string) (int, bool) {
+	for k, v := range ToneMappingNames {
+		if v == name {
+			return k, true
+		}
+	}
+
+	return 0, false
+}
+
+func (s *SphereWorld) NextToneMapping() {
+	s.Tone.Operator = (s.Tone.Operator + 1) % len(ToneMappingNames)
+	s.Invalidate()
+	log.Println("Tone mapping --->", ToneMappingNames[s.Tone.Operator])
+}
+
+func (s *SphereWorld) ModifyExposure(delta float32) {
+	s.Tone.Exposure += delta
+	s.Invalidate()
+	log.Printf("Exposure ---> %+.2f", s.Tone.Exposure)
+}
@@ -0,0 +1,55 @@
+package Camera
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToneMappingMap(t *testing.T) {
+	cases := []struct {
+		tone ToneMapping
+		v    float32
+		want float32
+	}{
+		{ToneMapping{Operator: ToneClamp}, 0.5, 0.5},
+		{ToneMapping{Operator: ToneClamp}, 2, 1},
+		{ToneMapping{Operator: ToneClamp}, -1, 0},
+		{ToneMapping{Operator: ToneClamp, Exposure: 1}, 0.25, 0.5},
+		{ToneMapping{Operator: ToneClamp, Exposure: -2}, 2, 0.5},
+		{ToneMapping{Operator: ToneReinhard}, 1, 0.5},
+		{ToneMapping{Operator: ToneReinhard}, 3, 0.75},
+		{ToneMapping{Operator: ToneReinhard, Exposure: 1}, 1.5, 0.75},
+		{ToneMapping{Operator: ToneACES}, 0, 0},
+		{ToneMapping{Operator: ToneACES}, 100, 1},
+	}
+
+	for _, c := range cases {
+		if got := c.tone.Map(c.v); math.Abs(float64(got-c.want)) > 1e-6 {
+			t.Errorf("%s exposure %v: Map(%v) = %v, want %v", ToneMappingNames[c.tone.Operator], c.tone.Exposure, c.v, got, c.want)
+		}
+	}
+}
+
+func TestToneMappingMonotonic(t *testing.T) {
+	for operator, name := range ToneMappingNames {
+		tone := ToneMapping{Operator: operator}
+		previous := tone.Map(0)
+		for v := float32(0.01); v < 20; v += 0.01 {
+			got := tone.Map(v)
+			if got < previous || got > 1 {
+				t.Errorf("%s: Map(%v) = %v after %v", name, v, got, previous)
+				break
+			}
+			previous = got
+		}
+	}
+}
+
+func TestFindToneMapping(t *testing.T) {
+	if k, ok := FindToneMapping("aces"); !ok || k != ToneACES {
+		t.Errorf("aces gave %d %t", k, ok)
+	}
+	if _, ok := FindToneMapping("filmic"); ok {
+		t.Error("found unknown tone mapping")
+	}
+}
@@ -0,0 +1,179 @@
+package Editor
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/kanister10l/GoCamera/Camera"
+	"github.com/kanister10l/GoCamera/History"
+	"github.com/kanister10l/GoCamera/World"
+)
+
+const (
+	ModeMove = iota
+	ModeRotate
+	ModeScale
+)
+
+var modeNames = []string{"move", "rotate", "scale"}
+
+type Editor struct {
+	Active   bool
+	Selected int
+	Mode     int
+	SavePath string
+	Camera   *Camera.Camera
+	World    *World.World
+	History  *History.History
+}
+
+// NewEditor creates an editor whose world changes are recorded in history.
+func NewEditor(camera *Camera.Camera, world *World.World, history *History.History, savePath string) *Editor {
+	editor := &Editor{}
+	editor.Selected = -1
+	editor.Mode = ModeMove
+	editor.SavePath = savePath
+	editor.Camera = camera
+	editor.World = world
+	editor.History = history
+
+	return editor
+}
+
+func (e *Editor) Toggle() {
+	e.Active = !e.Active
+	log.Println("Edit mode:", e.Active)
+}
+
+func (e *Editor) NextMode() {
+	e.Mode = (e.Mode + 1) % len(modeNames)
+	log.Println("Edit tool --->", modeNames[e.Mode])
+}
+
+// Select picks the entity under the window coordinate, or clears the
+// selection when there is none.
+func (e *Editor) Select(x, y, width, height float32) {
+	hit, ok := e.Camera.Pick(e.World, x, y, width, height)
+	if !ok {
+		e.Selected = -1
+		log.Println("Selection cleared")
+		return
+	}
+
+	e.Selected = hit.Entity
+	log.Printf("Selected entity %d %q", hit.Entity, e.World.Entities[hit.Entity].Name)
+}
+
+// Adjust applies one step of the current tool along the given axis
+// directions, each of which is -1, 0 or 1.
+func (e *Editor) Adjust(x, y, z float32) {
+	if e.Selected == -1 {
+		log.Println("No entity selected")
+		return
+	}
+
+	t := e.World.Entities[e.Selected].Transform
+	if e.Mode == ModeMove {
+		t.Translation.X += x * 0.1
+		t.Translation.Y += y * 0.1
+		t.Translation.Z += z * 0.1
+	} else if e.Mode == ModeRotate {
+		t.Rotation.X += x * 5
+		t.Rotation.Y += y * 5
+		t.Rotation.Z += z * 5
+	} else {
+		t.Scale.X *= float32(math.Pow(1.1, float64(x)))
+		t.Scale.Y *= float32(math.Pow(1.1, float64(y)))
+		t.Scale.Z *= float32(math.Pow(1.1, float64(z)))
+	}
+
+	e.SetTransform(e.Selected, t)
+}
+
+// SetTransform records the change as a snapshot named after the entity, so
+// that the steps of a held key are merged into one undo step.
+func (e *Editor) SetTransform(k int, t World.Transform) {
+	e.History.Run(History.Scoped(History.ScopeWorld, History.NewSnapshotCommand(fmt.Sprintf("transform entity %d", k), func() {
+		e.setTransform(k, t)
+	}, func() interface{} {
+		return e.World.Entities[k].Transform
+	}, func(state interface{}) {
+		e.setTransform(k, state.(World.Transform))
+	})))
+}
+
+func (e *Editor) setTransform(k int, t World.Transform) {
+	e.World.SetTransform(k, t)
+	e.Selected = k
+
+	log.Printf(`
+	Entity      ---> %d %q
+	Translation ---> %v
+	Rotation    ---> %v
+	Scale       ---> %v
+	`, k, e.World.Entities[k].Name, t.Translation, t.Rotation, t.Scale)
+}
+
+// AddBox adds a unit box centered on the surface at the middle of the screen,
+// or three units in front of the camera when nothing is there, and selects it.
+func (e *Editor) AddBox() {
+	ray := e.Camera.ScreenRay(0.5, 0.5, 1, 1)
+	target := ray.At(3)
+	if hit, ok := e.World.Raycast(ray); ok {
+		target = hit.Point
+	}
+
+	square := World.Square{
+		Origin: World.Origin{X: target.X - 0.5, Y: target.Y - 0.5, Z: target.Z - 0.5},
+		Height: 1,
+		Width:  1,
+		Depth:  1,
+	}
+
+	k := len(e.World.Entities)
+	entity := World.Entity{}
+	e.History.Run(History.Scoped(History.ScopeWorld, History.NewCommand("add box", func() {
+		if entity.Type == "" {
+			e.World.BuildSquare(square)
+			e.World.Entities[k].Name = e.World.UnusedName("box")
+			entity = e.World.Entities[k]
+		} else {
+			e.World.InsertEntity(k, entity)
+		}
+		e.Selected = k
+		log.Printf("Added entity %d %q", k, entity.Name)
+	}, func() {
+		e.World.RemoveEntity(k)
+		e.Selected = -1
+	})))
+}
+
+func (e *Editor) Delete() {
+	if e.Selected == -1 {
+		log.Println("No entity selected")
+		return
+	}
+
+	k := e.Selected
+	entity := e.World.Entities[k]
+	e.History.Run(History.Scoped(History.ScopeWorld, History.NewCommand("delete entity", func() {
+		e.World.RemoveEntity(k)
+		e.Selected = -1
+		log.Printf("Deleted entity %d %q", k, entity.Name)
+	}, func() {
+		e.World.InsertEntity(k, entity)
+		e.Selected = k
+	})))
+}
+
+func (e *Editor) Save() error {
+	err := e.World.Save(e.SavePath)
+	if err != nil {
+		log.Println("Error saving world:", err)
+		return err
+	}
+
+	log.Println("World saved to", e.SavePath)
+	return nil
+}
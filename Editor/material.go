@@ -0,0 +1,71 @@
+package Editor
+
+import (
+	"log"
+
+	"github.com/kanister10l/GoCamera/Camera"
+	"github.com/kanister10l/GoCamera/History"
+	"github.com/pkg/errors"
+)
+
+// MaterialEditor edits the materials of sphere mode, one color property of
+// the selected material at a time.
+type MaterialEditor struct {
+	Active   bool
+	Property int
+	SavePath string
+	Sphere   *Camera.SphereWorld
+	History  *History.History
+}
+
+// NewMaterialEditor creates a material editor whose changes are recorded in
+// history and which saves the library to savePath. An empty savePath refuses
+// to save, as used when the library could not be loaded.
+func NewMaterialEditor(sp *Camera.SphereWorld, history *History.History, savePath string) *MaterialEditor {
+	editor := &MaterialEditor{}
+	editor.Property = Camera.MaterialDiffuse
+	editor.SavePath = savePath
+	editor.Sphere = sp
+	editor.History = history
+
+	return editor
+}
+
+func (e *MaterialEditor) Toggle() {
+	e.Active = !e.Active
+	if e.Active {
+		e.Sphere.UseMaterials()
+	}
+	log.Println("Material edit mode:", e.Active)
+}
+
+func (e *MaterialEditor) NextProperty() {
+	e.Property = (e.Property + 1) % len(Camera.MaterialPropertyNames)
+	log.Println("Material property --->", Camera.MaterialPropertyNames[e.Property])
+}
+
+// Adjust changes the selected property of the current material by the given
+// steps of its red, green and blue and of the shininess.
+func (e *MaterialEditor) Adjust(r, g, b float32, shininess int) {
+	e.History.Run(History.ModifyMaterial(e.Sphere, e.Property, r*0.02, g*0.02, b*0.02, shininess))
+}
+
+func (e *MaterialEditor) Copy() {
+	e.History.Run(History.CopyMaterial(e.Sphere))
+}
+
+func (e *MaterialEditor) Save() error {
+	if e.SavePath == "" {
+		log.Println("Not saving the built-in materials over the library which failed to load, use -materialsave to pick a file")
+		return errors.New("no material save path")
+	}
+
+	err := Camera.SaveMaterialLibrary(e.SavePath, e.Sphere.Materials)
+	if err != nil {
+		log.Println("Error saving materials:", err)
+		return err
+	}
+
+	log.Println("Materials saved to", e.SavePath)
+	return nil
+}
@@ -49,3 +49,65 @@ func MakeVao(points []float32, color []float32, randColor bool) uint32 {
 func NormalizePosition(x, y, maxX, maxY float32) (float32, float32) {
 	return x / maxX, y / maxY
 }
+
+// MakeVaoAttributes binds every attribute, given as three components per
+// vertex, to the location of its position in the argument list.
+func MakeVaoAttributes(attributes ...[]float32) uint32 {
+	var vao uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+
+	for k, attribute := range attributes {
+		var vbo uint32
+		gl.GenBuffers(1, &vbo)
+		gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+		gl.BufferData(gl.ARRAY_BUFFER, 4*len(attribute), gl.Ptr(attribute), gl.STATIC_DRAW)
+		gl.EnableVertexAttribArray(uint32(k))
+		gl.VertexAttribPointer(uint32(k), 3, gl.FLOAT, false, 0, nil)
+	}
+
+	return vao
+}
+
+// VertexArray is a vertex array of positions and colors, three components
+// per vertex, whose buffers are filled again instead of making new ones.
+type VertexArray struct {
+	Vao       uint32
+	Positions uint32
+	Colors    uint32
+	Count     int32
+}
+
+func (v *VertexArray) Fill(points []float32, color []float32) {
+	if v.Vao == 0 {
+		gl.GenBuffers(1, &v.Positions)
+		gl.GenBuffers(1, &v.Colors)
+		gl.GenVertexArrays(1, &v.Vao)
+		gl.BindVertexArray(v.Vao)
+		gl.EnableVertexAttribArray(0)
+		gl.EnableVertexAttribArray(1)
+		gl.BindBuffer(gl.ARRAY_BUFFER, v.Positions)
+		gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 0, nil)
+		gl.BindBuffer(gl.ARRAY_BUFFER, v.Colors)
+		gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 0, nil)
+	}
+
+	v.Count = int32(len(points) / 3)
+	if v.Count == 0 {
+		return
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, v.Positions)
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(points), gl.Ptr(points), gl.STATIC_DRAW)
+	gl.BindBuffer(gl.ARRAY_BUFFER, v.Colors)
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(color), gl.Ptr(color), gl.STATIC_DRAW)
+}
+
+func (v *VertexArray) Draw() {
+	if v.Count == 0 {
+		return
+	}
+
+	gl.BindVertexArray(v.Vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, v.Count)
+}
@@ -0,0 +1,139 @@
+package History
+
+import "github.com/kanister10l/GoCamera/Camera"
+
+// SphereCommand records a change of the sphere world parameters, like
+// ModifyConstant, SelectNextMaterial or Rotate. The material library is not
+// part of the snapshot, as it can be reloaded in the meantime, changes of it
+// are recorded by MaterialCommand.
+func SphereCommand(sp *Camera.SphereWorld, name string, change func()) Command {
+	return NewSnapshotCommand(name, change, func() interface{} {
+		state := *sp
+		state.Lights = append([]Camera.SphereLight{}, sp.Lights...)
+		return state
+	}, func(state interface{}) {
+		sp.Restore(state.(Camera.SphereWorld))
+	})
+}
+
+func RotateLight(sp *Camera.SphereWorld, horizontalDelta, verticalDelta float32) Command {
+	return SphereCommand(sp, "rotate light", func() {
+		sp.Rotate(horizontalDelta, verticalDelta)
+	})
+}
+
+func AddLight(sp *Camera.SphereWorld) Command {
+	return SphereCommand(sp, "add light", sp.AddLight)
+}
+
+func RemoveLight(sp *Camera.SphereWorld) Command {
+	return SphereCommand(sp, "remove light", sp.RemoveLight)
+}
+
+func NextLight(sp *Camera.SphereWorld) Command {
+	return SphereCommand(sp, "select light", sp.NextLight)
+}
+
+func ToggleLight(sp *Camera.SphereWorld) Command {
+	return SphereCommand(sp, "toggle light", sp.ToggleLight)
+}
+
+func NextLightType(sp *Camera.SphereWorld) Command {
+	return SphereCommand(sp, "change light type", sp.NextLightType)
+}
+
+func ModifyLightIntensity(sp *Camera.SphereWorld, delta float32) Command {
+	return SphereCommand(sp, "modify light intensity", func() {
+		sp.ModifyLightIntensity(delta)
+	})
+}
+
+func ModifyConstant(sp *Camera.SphereWorld, a, d, s, h float32, n int) Command {
+	return SphereCommand(sp, "modify constant", func() {
+		sp.ModifyConstant(a, d, s, h, n)
+	})
+}
+
+func NextShadingModel(sp *Camera.SphereWorld) Command {
+	return SphereCommand(sp, "select shading model", sp.NextShadingModel)
+}
+
+func NextToneMapping(sp *Camera.SphereWorld) Command {
+	return SphereCommand(sp, "select tone mapping", sp.NextToneMapping)
+}
+
+func ModifyExposure(sp *Camera.SphereWorld, delta float32) Command {
+	return SphereCommand(sp, "modify exposure", func() {
+		sp.ModifyExposure(delta)
+	})
+}
+
+func SelectNextMaterial(sp *Camera.SphereWorld) Command {
+	return SphereCommand(sp, "select material", sp.SelectNextMaterial)
+}
+
+type materialState struct {
+	Materials Camera.Material
+	Selected  int
+	Prepared  bool
+}
+
+// MaterialCommand records a change of the material library together with the
+// selected material. It is in ScopeMaterials, as undoing it after a reload
+// would bring back the old library.
+func MaterialCommand(sp *Camera.SphereWorld, name string, change func()) Command {
+	return Scoped(ScopeMaterials, NewSnapshotCommand(name, change, func() interface{} {
+		return materialState{
+			Materials: append(Camera.Material{}, sp.Materials...),
+			Selected:  sp.SelectedMaterial,
+			Prepared:  sp.Prepared,
+		}
+	}, func(state interface{}) {
+		s := state.(materialState)
+		sp.SetMaterials(append(Camera.Material{}, s.Materials...))
+		sp.SelectedMaterial = s.Selected
+		sp.Prepared = s.Prepared
+		sp.Invalidate()
+	}))
+}
+
+func ModifyMaterial(sp *Camera.SphereWorld, property int, r, g, b float32, shininess int) Command {
+	return MaterialCommand(sp, "modify material", func() {
+		sp.ModifyMaterial(property, r, g, b, shininess)
+	})
+}
+
+func CopyMaterial(sp *Camera.SphereWorld) Command {
+	return MaterialCommand(sp, "copy material", sp.CopyMaterial)
+}
+
+type cameraState struct {
+	View      Camera.Bookmark
+	Bookmarks []Camera.Bookmark
+	Selected  int
+}
+
+// BookmarkCommand records a change of the camera bookmarks together with the
+// view, like AddBookmark or NextBookmark.
+func BookmarkCommand(camera *Camera.Camera, name string, change func()) Command {
+	return NewSnapshotCommand(name, change, func() interface{} {
+		return cameraState{
+			View:      camera.CurrentView(),
+			Bookmarks: append([]Camera.Bookmark{}, camera.Bookmarks...),
+			Selected:  camera.SelectedBookmark,
+		}
+	}, func(state interface{}) {
+		s := state.(cameraState)
+		camera.GoTo(s.View)
+		camera.Bookmarks = append([]Camera.Bookmark{}, s.Bookmarks...)
+		camera.SelectedBookmark = s.Selected
+	})
+}
+
+func AddBookmark(camera *Camera.Camera) Command {
+	return BookmarkCommand(camera, "add camera bookmark", camera.AddBookmark)
+}
+
+func NextBookmark(camera *Camera.Camera) Command {
+	return BookmarkCommand(camera, "go to camera bookmark", camera.NextBookmark)
+}
@@ -0,0 +1,209 @@
+package History
+
+import "log"
+
+// Command is a reversible change. Do is called when the command is run for
+// the first time and again on every redo.
+type Command interface {
+	Name() string
+	Do()
+	Undo()
+}
+
+// History is a bounded stack of executed commands with a redo stack.
+type History struct {
+	Limit  int
+	done   []Command
+	undone []Command
+	sealed bool
+}
+
+func NewHistory(limit int) *History {
+	history := &History{}
+	history.Limit = limit
+	history.done = []Command{}
+	history.undone = []Command{}
+
+	return history
+}
+
+// Run executes the command and records it, discarding the redo stack and the
+// oldest commands over the limit. A snapshot command named like the last one
+// is merged into it until Seal is called, so that the repeats of a held key
+// are undone in one step.
+func (h *History) Run(c Command) {
+	c.Do()
+
+	if !h.sealed && len(h.done) > 0 && len(h.undone) == 0 {
+		last, ok := snapshot(h.done[len(h.done)-1])
+		next, nextOk := snapshot(c)
+		if ok && nextOk && last.merge(next) {
+			return
+		}
+	}
+	h.sealed = false
+
+	h.done = append(h.done, c)
+	if h.Limit > 0 && len(h.done) > h.Limit {
+		h.done = h.done[len(h.done)-h.Limit:]
+	}
+	h.undone = []Command{}
+}
+
+func (h *History) Undo() bool {
+	if len(h.done) == 0 {
+		log.Println("Nothing to undo")
+		return false
+	}
+
+	c := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	c.Undo()
+	h.sealed = true
+	h.undone = append(h.undone, c)
+
+	log.Println("Undo --->", c.Name())
+	return true
+}
+
+func (h *History) Redo() bool {
+	if len(h.undone) == 0 {
+		log.Println("Nothing to redo")
+		return false
+	}
+
+	c := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	c.Do()
+	h.done = append(h.done, c)
+	h.sealed = true
+
+	log.Println("Redo --->", c.Name())
+	return true
+}
+
+// Seal ends merging into the last command, for example when a key is
+// released.
+func (h *History) Seal() {
+	h.sealed = true
+}
+
+// Clear forgets all commands.
+func (h *History) Clear() {
+	h.done = []Command{}
+	h.undone = []Command{}
+}
+
+// ClearScope forgets the commands of scope, for example after the world they
+// refer to was replaced, and keeps the others.
+func (h *History) ClearScope(scope string) {
+	h.done = withoutScope(h.done, scope)
+	h.undone = withoutScope(h.undone, scope)
+	h.sealed = true
+}
+
+func withoutScope(commands []Command, scope string) []Command {
+	kept := []Command{}
+	for _, c := range commands {
+		if s, ok := c.(*scopedCommand); !ok || s.scope != scope {
+			kept = append(kept, c)
+		}
+	}
+
+	return kept
+}
+
+// Scopes of commands which refer to data a reload replaces.
+const (
+	ScopeWorld     = "world"
+	ScopeMaterials = "materials"
+)
+
+type scopedCommand struct {
+	Command
+	scope string
+}
+
+// Scoped marks a command as referring to scope, so that ClearScope forgets
+// it.
+func Scoped(scope string, c Command) Command {
+	return &scopedCommand{Command: c, scope: scope}
+}
+
+func snapshot(c Command) (*snapshotCommand, bool) {
+	if s, ok := c.(*scopedCommand); ok {
+		c = s.Command
+	}
+	s, ok := c.(*snapshotCommand)
+
+	return s, ok
+}
+
+type funcCommand struct {
+	name string
+	do   func()
+	undo func()
+}
+
+// NewCommand builds a command from a pair of functions.
+func NewCommand(name string, do, undo func()) Command {
+	return &funcCommand{name: name, do: do, undo: undo}
+}
+
+func (c *funcCommand) Name() string {
+	return c.name
+}
+
+func (c *funcCommand) Do() {
+	c.do()
+}
+
+func (c *funcCommand) Undo() {
+	c.undo()
+}
+
+type snapshotCommand struct {
+	name    string
+	change  func()
+	save    func() interface{}
+	restore func(interface{})
+	before  interface{}
+	after   interface{}
+}
+
+// NewSnapshotCommand builds a command for changes which are hard to invert,
+// like clamped adjustments. The state is saved before and after the first run
+// of change and restored on undo and redo.
+func NewSnapshotCommand(name string, change func(), save func() interface{}, restore func(interface{})) Command {
+	return &snapshotCommand{name: name, change: change, save: save, restore: restore}
+}
+
+func (c *snapshotCommand) Name() string {
+	return c.name
+}
+
+func (c *snapshotCommand) Do() {
+	if c.after != nil {
+		c.restore(c.after)
+		return
+	}
+
+	c.before = c.save()
+	c.change()
+	c.after = c.save()
+}
+
+func (c *snapshotCommand) Undo() {
+	c.restore(c.before)
+}
+
+// merge takes over the final state of next when it has the same name, and
+// with it the same kind of snapshot.
+func (c *snapshotCommand) merge(next *snapshotCommand) bool {
+	if c.name != next.name {
+		return false
+	}
+
+	c.after = next.after
+	return true
+}
@@ -0,0 +1,123 @@
+package History
+
+import "testing"
+
+func add(value *int, name string, delta int) Command {
+	return NewSnapshotCommand(name, func() {
+		*value += delta
+	}, func() interface{} {
+		return *value
+	}, func(state interface{}) {
+		*value = state.(int)
+	})
+}
+
+func set(value *int, v int) Command {
+	before := *value
+	return NewCommand("set", func() { *value = v }, func() { *value = before })
+}
+
+func TestUndoRedoBounds(t *testing.T) {
+	value := 0
+	h := NewHistory(3)
+	for k := 1; k <= 5; k++ {
+		h.Run(set(&value, k))
+	}
+
+	undone := 0
+	for h.Undo() {
+		undone++
+	}
+	if undone != 3 || value != 2 {
+		t.Errorf("undid %d commands to %d, want 3 to 2", undone, value)
+	}
+
+	if !h.Redo() || value != 3 {
+		t.Errorf("redo gave %d, want 3", value)
+	}
+
+	h.Run(set(&value, 10))
+	if h.Redo() {
+		t.Error("redo after run, want the redo stack discarded")
+	}
+
+	h.Clear()
+	if h.Undo() || h.Redo() {
+		t.Error("undo or redo after clear")
+	}
+}
+
+func TestMergeRepeatedCommands(t *testing.T) {
+	cases := []struct {
+		name  string
+		run   func(h *History, value *int)
+		steps int
+	}{
+		{"repeats", func(h *History, value *int) {
+			for k := 0; k < 5; k++ {
+				h.Run(add(value, "adjust", 1))
+			}
+		}, 1},
+		{"sealed", func(h *History, value *int) {
+			h.Run(add(value, "adjust", 1))
+			h.Run(add(value, "adjust", 1))
+			h.Seal()
+			h.Run(add(value, "adjust", 1))
+		}, 2},
+		{"other names", func(h *History, value *int) {
+			h.Run(add(value, "adjust", 1))
+			h.Run(add(value, "rotate", 1))
+			h.Run(add(value, "adjust", 1))
+		}, 3},
+		{"function commands", func(h *History, value *int) {
+			for k := 0; k < 3; k++ {
+				h.Run(NewCommand("adjust", func() { *value++ }, func() { *value-- }))
+			}
+		}, 3},
+		{"scoped", func(h *History, value *int) {
+			h.Run(Scoped(ScopeWorld, add(value, "adjust", 1)))
+			h.Run(Scoped(ScopeWorld, add(value, "adjust", 1)))
+		}, 1},
+		{"after undo", func(h *History, value *int) {
+			h.Run(add(value, "adjust", 1))
+			h.Run(add(value, "adjust", 1))
+			h.Undo()
+			h.Run(add(value, "adjust", 1))
+		}, 1},
+	}
+
+	for _, c := range cases {
+		value := 0
+		h := NewHistory(100)
+		c.run(h, &value)
+		if len(h.done) != c.steps {
+			t.Errorf("%s: %d undo steps, want %d", c.name, len(h.done), c.steps)
+		}
+
+		for h.Undo() {
+		}
+		if value != 0 {
+			t.Errorf("%s: undo all gave %d, want 0", c.name, value)
+		}
+	}
+}
+
+func TestClearScope(t *testing.T) {
+	world, materials, other := 0, 0, 0
+	h := NewHistory(100)
+	h.Run(set(&other, 1))
+	h.Run(Scoped(ScopeWorld, set(&world, 1)))
+	h.Run(Scoped(ScopeMaterials, set(&materials, 1)))
+	h.Run(Scoped(ScopeWorld, set(&world, 2)))
+	h.Undo()
+
+	h.ClearScope(ScopeWorld)
+	if h.Redo() {
+		t.Error("redo of a cleared world command")
+	}
+	for h.Undo() {
+	}
+	if world != 1 || materials != 0 || other != 0 {
+		t.Errorf("undo all gave world %d materials %d other %d, want 1 0 0", world, materials, other)
+	}
+}
@@ -1,14 +1,29 @@
 package KeyCallbacks
 
 import (
+	"log"
+
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/kanister10l/GoCamera/Camera"
+	"github.com/kanister10l/GoCamera/Editor"
+	"github.com/kanister10l/GoCamera/History"
 	"github.com/kanister10l/GoCamera/World"
 )
 
-func SetCallbacks(window *glfw.Window, camera *Camera.Camera, world *World.World, sp *Camera.SphereWorld) {
+func SetCallbacks(window *glfw.Window, camera *Camera.Camera, world *World.World, sp *Camera.SphereWorld, tessellation *Camera.SphereTessellation, editor *Editor.Editor, materialEditor *Editor.MaterialEditor, history *History.History) {
 	window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		if action == glfw.Release {
+			history.Seal()
+		}
+
 		if action == 1 || action == 2 {
+			if camera.DrawType != 2 && editorKeys(editor, key, action) {
+				return
+			}
+			if camera.DrawType == 2 && materialKeys(materialEditor, key, action) {
+				return
+			}
+
 			if key == glfw.KeyD {
 				transValue := Camera.RotateVector3D([]float32{0.03, 0.0, 0.0}, camera.Rotation)
 				camera.Translate(transValue[0], transValue[1], transValue[2])
@@ -61,36 +76,99 @@ func SetCallbacks(window *glfw.Window, camera *Camera.Camera, world *World.World
 			} else if key == glfw.KeyPageUp {
 				camera.SphereDrawType()
 			} else if key == glfw.KeyKP8 {
-				sp.Rotate(0, 0.0175)
+				history.Run(History.RotateLight(sp, 0, 0.0175))
 			} else if key == glfw.KeyKP2 {
-				sp.Rotate(0, -0.0175)
+				history.Run(History.RotateLight(sp, 0, -0.0175))
 			} else if key == glfw.KeyKP6 {
-				sp.Rotate(0.0175, 0)
+				history.Run(History.RotateLight(sp, 0.0175, 0))
 			} else if key == glfw.KeyKP4 {
-				sp.Rotate(-0.0175, 0)
+				history.Run(History.RotateLight(sp, -0.0175, 0))
+			} else if key == glfw.KeyL {
+				history.Run(History.NextLight(sp))
+			} else if key == glfw.KeyK {
+				history.Run(History.AddLight(sp))
+			} else if key == glfw.KeyO {
+				history.Run(History.RemoveLight(sp))
+			} else if key == glfw.KeyI {
+				history.Run(History.ToggleLight(sp))
+			} else if key == glfw.KeyT {
+				history.Run(History.NextLightType(sp))
+			} else if key == glfw.KeyKPAdd {
+				history.Run(History.ModifyLightIntensity(sp, 0.1))
+			} else if key == glfw.KeyKPSubtract {
+				history.Run(History.ModifyLightIntensity(sp, -0.1))
 			} else if key == glfw.Key1 {
-				sp.ModifyConstant(0, 0, 0, -0.02, 0)
+				history.Run(History.ModifyConstant(sp, 0, 0, 0, -0.02, 0))
 			} else if key == glfw.Key2 {
-				sp.ModifyConstant(0, 0, 0, 0.02, 0)
+				history.Run(History.ModifyConstant(sp, 0, 0, 0, 0.02, 0))
 			} else if key == glfw.Key3 {
-				sp.ModifyConstant(-0.02, 0, 0, 0, 0)
+				history.Run(History.ModifyConstant(sp, -0.02, 0, 0, 0, 0))
 			} else if key == glfw.Key4 {
-				sp.ModifyConstant(0.02, 0, 0, 0, 0)
+				history.Run(History.ModifyConstant(sp, 0.02, 0, 0, 0, 0))
 			} else if key == glfw.Key5 {
-				sp.ModifyConstant(0, -0.02, 0, 0, 0)
+				history.Run(History.ModifyConstant(sp, 0, -0.02, 0, 0, 0))
 			} else if key == glfw.Key6 {
-				sp.ModifyConstant(0, 0.02, 0, 0, 0)
+				history.Run(History.ModifyConstant(sp, 0, 0.02, 0, 0, 0))
 			} else if key == glfw.Key7 {
-				sp.ModifyConstant(0, 0, -0.02, 0, 0)
+				history.Run(History.ModifyConstant(sp, 0, 0, -0.02, 0, 0))
 			} else if key == glfw.Key8 {
-				sp.ModifyConstant(0, 0, 0.02, 0, 0)
+				history.Run(History.ModifyConstant(sp, 0, 0, 0.02, 0, 0))
 			} else if key == glfw.Key9 {
-				sp.ModifyConstant(0, 0, 0, 0, -1)
+				history.Run(History.ModifyConstant(sp, 0, 0, 0, 0, -1))
 			} else if key == glfw.Key0 {
-				sp.ModifyConstant(0, 0, 0, 0, 1)
+				history.Run(History.ModifyConstant(sp, 0, 0, 0, 0, 1))
 			} else if key == glfw.KeyM {
-				sp.SelectNextMaterial()
+				history.Run(History.SelectNextMaterial(sp))
+			} else if key == glfw.KeyKPMultiply {
+				tessellation.Refine(1)
+			} else if key == glfw.KeyKPDivide {
+				tessellation.Refine(-1)
+			} else if key == glfw.KeyKPEnter {
+				tessellation.ToggleAuto()
+			} else if key == glfw.KeyKP5 {
+				history.Run(History.NextToneMapping(sp))
+			} else if key == glfw.KeyKP7 {
+				history.Run(History.ModifyExposure(sp, -0.25))
+			} else if key == glfw.KeyKP9 {
+				history.Run(History.ModifyExposure(sp, 0.25))
+			} else if key == glfw.KeyV {
+				history.Run(History.NextShadingModel(sp))
+			} else if key == glfw.KeyC {
+				sp.ToggleCompare()
+			} else if key == glfw.KeyF {
+				camera.ToggleGammaCorrect()
+			} else if key == glfw.KeyG {
+				camera.NextShadingMode()
+			} else if key == glfw.KeyB {
+				history.Run(History.AddBookmark(camera))
+			} else if key == glfw.KeyN {
+				history.Run(History.NextBookmark(camera))
+			} else if key == glfw.KeyZ {
+				history.Undo()
+			} else if key == glfw.KeyX {
+				history.Redo()
+			} else if key == glfw.KeyP {
+				log.Printf("Entities culled: %d, drawn: %d", camera.Stats.Culled, camera.Stats.Drawn)
 			}
 		}
 	})
+	window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		if button == glfw.MouseButtonLeft && action == glfw.Press && camera.DrawType != 2 {
+			x, y := w.GetCursorPos()
+			width, height := w.GetSize()
+			if editor.Active {
+				editor.Select(float32(x), float32(y), float32(width), float32(height))
+				return
+			}
+
+			hit, ok := camera.Pick(world, float32(x), float32(y), float32(width), float32(height))
+			if !ok {
+				log.Println("Nothing picked")
+				return
+			}
+
+			log.Printf("Picked entity %d %q face %d at (%f, %f, %f), distance %f",
+				hit.Entity, world.Entities[hit.Entity].Name, hit.Face, hit.Point.X, hit.Point.Y, hit.Point.Z, hit.Distance)
+		}
+	})
 }
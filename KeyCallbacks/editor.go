@@ -0,0 +1,55 @@
+package KeyCallbacks
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/kanister10l/GoCamera/Editor"
+)
+
+// editorKeys handles keys of the scene editor and reports whether the key was
+// consumed. Apart from the toggle they only work while edit mode is active.
+func editorKeys(editor *Editor.Editor, key glfw.Key, action glfw.Action) bool {
+	if key == glfw.KeyE {
+		if action == glfw.Press {
+			editor.Toggle()
+		}
+		return true
+	}
+
+	if !editor.Active {
+		return false
+	}
+
+	if key == glfw.KeyTab {
+		if action == glfw.Press {
+			editor.NextMode()
+		}
+	} else if key == glfw.KeyKP4 {
+		editor.Adjust(-1, 0, 0)
+	} else if key == glfw.KeyKP6 {
+		editor.Adjust(1, 0, 0)
+	} else if key == glfw.KeyKP8 {
+		editor.Adjust(0, -1, 0)
+	} else if key == glfw.KeyKP2 {
+		editor.Adjust(0, 1, 0)
+	} else if key == glfw.KeyKP7 {
+		editor.Adjust(0, 0, -1)
+	} else if key == glfw.KeyKP9 {
+		editor.Adjust(0, 0, 1)
+	} else if key == glfw.KeyInsert {
+		if action == glfw.Press {
+			editor.AddBox()
+		}
+	} else if key == glfw.KeyDelete {
+		if action == glfw.Press {
+			editor.Delete()
+		}
+	} else if key == glfw.KeyF2 {
+		if action == glfw.Press {
+			editor.Save()
+		}
+	} else {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,60 @@
+package KeyCallbacks
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/kanister10l/GoCamera/Editor"
+)
+
+// materialKeys handles keys of the material editor in sphere mode and reports
+// whether the key was consumed. Apart from the toggle they only work while
+// material edit mode is active.
+func materialKeys(editor *Editor.MaterialEditor, key glfw.Key, action glfw.Action) bool {
+	if key == glfw.KeyQ {
+		if action == glfw.Press {
+			editor.Toggle()
+		}
+		return true
+	}
+
+	if !editor.Active {
+		return false
+	}
+
+	if key == glfw.KeyTab {
+		if action == glfw.Press {
+			editor.NextProperty()
+		}
+	} else if key == glfw.Key1 {
+		editor.Adjust(-1, 0, 0, 0)
+	} else if key == glfw.Key2 {
+		editor.Adjust(1, 0, 0, 0)
+	} else if key == glfw.Key3 {
+		editor.Adjust(0, -1, 0, 0)
+	} else if key == glfw.Key4 {
+		editor.Adjust(0, 1, 0, 0)
+	} else if key == glfw.Key5 {
+		editor.Adjust(0, 0, -1, 0)
+	} else if key == glfw.Key6 {
+		editor.Adjust(0, 0, 1, 0)
+	} else if key == glfw.Key7 {
+		editor.Adjust(-1, -1, -1, 0)
+	} else if key == glfw.Key8 {
+		editor.Adjust(1, 1, 1, 0)
+	} else if key == glfw.Key9 {
+		editor.Adjust(0, 0, 0, -1)
+	} else if key == glfw.Key0 {
+		editor.Adjust(0, 0, 0, 1)
+	} else if key == glfw.KeyInsert {
+		if action == glfw.Press {
+			editor.Copy()
+		}
+	} else if key == glfw.KeyF2 {
+		if action == glfw.Press {
+			editor.Save()
+		}
+	} else {
+		return false
+	}
+
+	return true
+}
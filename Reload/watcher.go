@@ -0,0 +1,80 @@
+package Reload
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Watcher polls a set of files and calls a function from its own goroutine
+// whenever one of them is modified, created or removed.
+type Watcher struct {
+	mutex   sync.Mutex
+	files   map[string]fileState
+	changed func()
+}
+
+type fileState struct {
+	ModTime time.Time
+	Size    int64
+	Exists  bool
+}
+
+func Watch(files []string, interval time.Duration, changed func()) *Watcher {
+	watcher := &Watcher{}
+	watcher.files = map[string]fileState{}
+	watcher.changed = changed
+	watcher.SetFiles(files)
+
+	go func() {
+		for range time.Tick(interval) {
+			if watcher.poll() {
+				watcher.changed()
+			}
+		}
+	}()
+
+	return watcher
+}
+
+// SetFiles replaces the watched files. Files which were already watched keep
+// their last seen state, so changes made in between are not lost.
+func (w *Watcher) SetFiles(files []string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	newFiles := map[string]fileState{}
+	for _, file := range files {
+		if state, ok := w.files[file]; ok {
+			newFiles[file] = state
+		} else {
+			newFiles[file] = stat(file)
+		}
+	}
+	w.files = newFiles
+}
+
+func (w *Watcher) poll() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	changed := false
+	for file, state := range w.files {
+		newState := stat(file)
+		if newState != state {
+			w.files[file] = newState
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func stat(file string) fileState {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileState{}
+	}
+
+	return fileState{ModTime: info.ModTime(), Size: info.Size(), Exists: true}
+}
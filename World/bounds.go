@@ -0,0 +1,91 @@
+package World
+
+import "math"
+
+// UpdateBounds recalculates the bounding box and sphere of the entity. It has
+// to be called whenever the points of the entity change.
+func (e *Entity) UpdateBounds() {
+	e.faceIndex = nil
+	e.normals = nil
+
+	if len(e.Points) == 0 {
+		e.Box = AABB{}
+		e.Sphere = BoundingSphere{}
+		return
+	}
+
+	min := Origin{X: e.Points[0].X, Y: e.Points[0].Y, Z: e.Points[0].Z}
+	max := min
+	for _, p := range e.Points[1:] {
+		if p.X < min.X {
+			min.X = p.X
+		} else if p.X > max.X {
+			max.X = p.X
+		}
+		if p.Y < min.Y {
+			min.Y = p.Y
+		} else if p.Y > max.Y {
+			max.Y = p.Y
+		}
+		if p.Z < min.Z {
+			min.Z = p.Z
+		} else if p.Z > max.Z {
+			max.Z = p.Z
+		}
+	}
+	e.Box = AABB{Min: min, Max: max}
+
+	center := e.Box.Center()
+	radius := float32(0)
+	for _, p := range e.Points {
+		d := (p.X-center.X)*(p.X-center.X) + (p.Y-center.Y)*(p.Y-center.Y) + (p.Z-center.Z)*(p.Z-center.Z)
+		if d > radius {
+			radius = d
+		}
+	}
+	e.Sphere = BoundingSphere{Center: center, Radius: float32(math.Sqrt(float64(radius)))}
+}
+
+func (b AABB) Center() Origin {
+	return Origin{
+		X: (b.Min.X + b.Max.X) / 2,
+		Y: (b.Min.Y + b.Max.Y) / 2,
+		Z: (b.Min.Z + b.Max.Z) / 2,
+	}
+}
+
+func (b AABB) Overlaps(o AABB) bool {
+	return b.Min.X <= o.Max.X && b.Max.X >= o.Min.X &&
+		b.Min.Y <= o.Max.Y && b.Max.Y >= o.Min.Y &&
+		b.Min.Z <= o.Max.Z && b.Max.Z >= o.Min.Z
+}
+
+// VertexNormals returns for every point the normalized sum of the normals of
+// the faces sharing it, weighted by face area.
+func (e *Entity) VertexNormals() []Origin {
+	if e.normals != nil {
+		return e.normals
+	}
+
+	e.normals = make([]Origin, len(e.Points))
+	for _, f := range e.Faces {
+		p1 := e.Points[f.P1]
+		p2 := e.Points[f.P2]
+		p3 := e.Points[f.P3]
+		n := cross(Origin{X: p2.X - p1.X, Y: p2.Y - p1.Y, Z: p2.Z - p1.Z}, Origin{X: p3.X - p1.X, Y: p3.Y - p1.Y, Z: p3.Z - p1.Z})
+		for _, p := range []int{f.P1, f.P2, f.P3} {
+			e.normals[p].X += n.X
+			e.normals[p].Y += n.Y
+			e.normals[p].Z += n.Z
+		}
+	}
+
+	for k, n := range e.normals {
+		l := float32(math.Sqrt(float64(dot(n, n))))
+		if l > 0 {
+			e.normals[k] = Origin{X: n.X / l, Y: n.Y / l, Z: n.Z / l}
+		}
+	}
+
+	return e.normals
+}
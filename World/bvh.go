@@ -0,0 +1,156 @@
+package World
+
+import "sort"
+
+const bvhLeafSize = 4
+
+// Volume is anything the spatial index can be queried with: a box, a ray or
+// the camera frustum.
+type Volume interface {
+	OverlapsBox(box AABB) bool
+}
+
+func (b AABB) OverlapsBox(box AABB) bool {
+	return b.Overlaps(box)
+}
+
+// BVH is a bounding volume hierarchy over a list of boxes. Queries report the
+// position of every box overlapping the volume in the list the BVH was built
+// from.
+type BVH struct {
+	nodes []bvhNode
+	items []bvhItem
+}
+
+type bvhNode struct {
+	Box   AABB
+	Left  int
+	Right int
+	First int
+	Count int
+}
+
+type bvhItem struct {
+	Box AABB
+	ID  int
+}
+
+func BuildBVH(boxes []AABB) *BVH {
+	b := &BVH{}
+	b.items = make([]bvhItem, len(boxes))
+	for k, box := range boxes {
+		b.items[k] = bvhItem{Box: box, ID: k}
+	}
+
+	if len(b.items) > 0 {
+		b.build(0, len(b.items))
+	}
+
+	return b
+}
+
+func (b *BVH) build(first, last int) int {
+	box := b.items[first].Box
+	for _, item := range b.items[first+1 : last] {
+		box = box.Union(item.Box)
+	}
+
+	k := len(b.nodes)
+	b.nodes = append(b.nodes, bvhNode{Box: box, Left: -1, Right: -1, First: first, Count: last - first})
+	if last-first <= bvhLeafSize {
+		return k
+	}
+
+	axis := box.LongestAxis()
+	items := b.items[first:last]
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Box.Center().Axis(axis) < items[j].Box.Center().Axis(axis)
+	})
+
+	middle := first + (last-first)/2
+	left := b.build(first, middle)
+	right := b.build(middle, last)
+	b.nodes[k].Left = left
+	b.nodes[k].Right = right
+	b.nodes[k].Count = 0
+
+	return k
+}
+
+// Query calls visit for every box overlapping the volume until visit returns
+// false. It reports whether the query ran to completion.
+func (b *BVH) Query(volume Volume, visit func(id int) bool) bool {
+	if b == nil || len(b.nodes) == 0 {
+		return true
+	}
+
+	stack := []int{0}
+	for len(stack) > 0 {
+		node := b.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+
+		if !volume.OverlapsBox(node.Box) {
+			continue
+		}
+
+		if node.Left == -1 {
+			for _, item := range b.items[node.First : node.First+node.Count] {
+				if volume.OverlapsBox(item.Box) && !visit(item.ID) {
+					return false
+				}
+			}
+			continue
+		}
+
+		stack = append(stack, node.Right, node.Left)
+	}
+
+	return true
+}
+
+func (b AABB) Union(o AABB) AABB {
+	if o.Min.X < b.Min.X {
+		b.Min.X = o.Min.X
+	}
+	if o.Min.Y < b.Min.Y {
+		b.Min.Y = o.Min.Y
+	}
+	if o.Min.Z < b.Min.Z {
+		b.Min.Z = o.Min.Z
+	}
+	if o.Max.X > b.Max.X {
+		b.Max.X = o.Max.X
+	}
+	if o.Max.Y > b.Max.Y {
+		b.Max.Y = o.Max.Y
+	}
+	if o.Max.Z > b.Max.Z {
+		b.Max.Z = o.Max.Z
+	}
+
+	return b
+}
+
+// LongestAxis returns 0, 1 or 2 for the X, Y or Z extent of the box.
+func (b AABB) LongestAxis() int {
+	x := b.Max.X - b.Min.X
+	y := b.Max.Y - b.Min.Y
+	z := b.Max.Z - b.Min.Z
+	if x >= y && x >= z {
+		return 0
+	} else if y >= z {
+		return 1
+	}
+
+	return 2
+}
+
+func (o Origin) Axis(axis int) float32 {
+	if axis == 0 {
+		return o.X
+	} else if axis == 1 {
+		return o.Y
+	}
+
+	return o.Z
+}
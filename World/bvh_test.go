@@ -0,0 +1,140 @@
+package World
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestBVHQueryMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	boxes := make([]AABB, 200)
+	for k := range boxes {
+		min := Origin{X: random.Float32() * 100, Y: random.Float32() * 100, Z: random.Float32() * 100}
+		boxes[k] = AABB{Min: min, Max: Origin{X: min.X + random.Float32()*5, Y: min.Y + random.Float32()*5, Z: min.Z + random.Float32()*5}}
+	}
+	bvh := BuildBVH(boxes)
+
+	volumes := []Volume{
+		AABB{Min: Origin{X: 10, Y: 10, Z: 10}, Max: Origin{X: 40, Y: 40, Z: 40}},
+		AABB{Min: Origin{X: -10, Y: -10, Z: -10}, Max: Origin{X: -1, Y: -1, Z: -1}},
+		Ray{Origin: Origin{X: 0, Y: 0, Z: 0}, Direction: Origin{X: 1, Y: 1, Z: 1}},
+		Ray{Origin: Origin{X: 50, Y: 50, Z: -10}, Direction: Origin{X: 0, Y: 0, Z: 1}},
+	}
+	for k, volume := range volumes {
+		want := []int{}
+		for id, box := range boxes {
+			if volume.OverlapsBox(box) {
+				want = append(want, id)
+			}
+		}
+
+		got := []int{}
+		if !bvh.Query(volume, func(id int) bool {
+			got = append(got, id)
+			return true
+		}) {
+			t.Errorf("volume %d: query did not complete", k)
+		}
+		sort.Ints(got)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("volume %d: found %v, want %v", k, got, want)
+		}
+	}
+}
+
+func TestBVHQueryStops(t *testing.T) {
+	boxes := []AABB{{}, {}, {}, {}, {}, {}}
+	visited := 0
+	complete := BuildBVH(boxes).Query(AABB{}, func(id int) bool {
+		visited++
+		return visited < 2
+	})
+	if complete || visited != 2 {
+		t.Errorf("complete %t after %d boxes, want false after 2", complete, visited)
+	}
+
+	if !BuildBVH(nil).Query(AABB{}, func(id int) bool { return false }) {
+		t.Error("query of an empty BVH did not complete")
+	}
+}
+
+func TestRayOverlapsBox(t *testing.T) {
+	box := AABB{Min: Origin{X: -1, Y: -1, Z: 4}, Max: Origin{X: 1, Y: 1, Z: 6}}
+	cases := []struct {
+		name string
+		ray  Ray
+		want bool
+	}{
+		{"hit", Ray{Direction: Origin{Z: 1}}, true},
+		{"diagonal hit", Ray{Direction: Origin{X: 0.1, Y: 0.1, Z: 1}}, true},
+		{"miss", Ray{Direction: Origin{X: 1, Z: 1}}, false},
+		{"behind", Ray{Direction: Origin{Z: -1}}, false},
+		{"parallel outside", Ray{Origin: Origin{X: 2}, Direction: Origin{Z: 1}}, false},
+		{"inside", Ray{Origin: Origin{Z: 5}, Direction: Origin{X: 1}}, true},
+	}
+
+	for _, c := range cases {
+		if got := c.ray.OverlapsBox(box); got != c.want {
+			t.Errorf("%s: %t, want %t", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRayIntersectFace(t *testing.T) {
+	entity := &Entity{
+		Points: []Point{{X: -1, Y: -1, Z: 5}, {X: 1, Y: -1, Z: 5}, {X: 0, Y: 1, Z: 5}},
+		Faces:  []Face{{P1: 0, P2: 1, P3: 2}},
+	}
+	cases := []struct {
+		name     string
+		ray      Ray
+		distance float32
+		hit      bool
+	}{
+		{"front", Ray{Direction: Origin{Z: 1}}, 5, true},
+		{"scaled direction", Ray{Direction: Origin{Z: 2}}, 2.5, true},
+		{"back side", Ray{Origin: Origin{Z: 10}, Direction: Origin{Z: -1}}, 5, true},
+		{"outside", Ray{Origin: Origin{X: 2}, Direction: Origin{Z: 1}}, 0, false},
+		{"behind", Ray{Direction: Origin{Z: -1}}, 0, false},
+		{"parallel", Ray{Direction: Origin{X: 1}}, 0, false},
+	}
+
+	for _, c := range cases {
+		distance, hit := c.ray.IntersectFace(entity, 0)
+		if hit != c.hit || distance != c.distance {
+			t.Errorf("%s: %v %t, want %v %t", c.name, distance, hit, c.distance, c.hit)
+		}
+	}
+}
+
+func TestRaycastAndCollides(t *testing.T) {
+	w := NewWorld()
+	w.BuildSquare(Square{Origin: Origin{X: -0.5, Y: -0.5, Z: 10}, Width: 1, Height: 1, Depth: 1})
+	w.BuildSquare(Square{Origin: Origin{X: -0.5, Y: -0.5, Z: 5}, Width: 1, Height: 1, Depth: 1})
+
+	ray := Ray{Direction: Origin{Z: 1}}
+	hit, ok := w.Raycast(ray)
+	if !ok || hit.Entity != 1 || hit.Distance != 5 {
+		t.Errorf("hit %+v %t, want entity 1 at 5", hit, ok)
+	}
+
+	w.Entities[1].Visible = false
+	hit, ok = w.Raycast(ray)
+	if !ok || hit.Entity != 0 || hit.Distance != 10 {
+		t.Errorf("hit %+v %t, want entity 0 at 10 past the hidden one", hit, ok)
+	}
+
+	if _, ok := w.Raycast(Ray{Direction: Origin{Z: -1}}); ok {
+		t.Error("hit behind the ray")
+	}
+
+	if !w.Collides(AABB{Min: Origin{X: 0, Y: 0, Z: 9.5}, Max: Origin{X: 0.2, Y: 0.2, Z: 10.5}}) {
+		t.Error("no collision with the visible box")
+	}
+	if w.Collides(AABB{Min: Origin{X: 0, Y: 0, Z: 4.5}, Max: Origin{X: 0.2, Y: 0.2, Z: 5.5}}) {
+		t.Error("collision with the hidden box")
+	}
+}
@@ -0,0 +1,67 @@
+package World
+
+import (
+	"encoding/json"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Include is the data of an "include" object. Path is resolved relative to
+// the including descriptor, and Namespace is prefixed to the names of all
+// entities built from the included file.
+type Include struct {
+	Path      string
+	Namespace string
+}
+
+func (w *World) buildInclude(f FileObject, worldDescriptor, namespace string, chain []string) error {
+	include := Include{}
+	err := json.Unmarshal([]byte(f.Data), &include)
+	if err != nil {
+		log.Println("Error parsing include object data:", err.Error())
+		return errors.Wrap(err, worldDescriptor)
+	}
+
+	if include.Path == "" {
+		return errors.Errorf("%s: include object without path", worldDescriptor)
+	}
+
+	path := include.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(worldDescriptor), path)
+	}
+
+	log.Println("Including world descriptor", path)
+
+	return w.buildFile(path, JoinNamespace(namespace, include.Namespace), chain)
+}
+
+// enterInclude appends the descriptor to the chain of files currently being
+// built and fails if it is already part of it.
+func enterInclude(worldDescriptor string, chain []string) ([]string, error) {
+	path, err := filepath.Abs(worldDescriptor)
+	if err != nil {
+		return chain, errors.Wrap(err, worldDescriptor)
+	}
+
+	for k, v := range chain {
+		if v == path {
+			cycle := append(append([]string{}, chain[k:]...), path)
+			return chain, errors.Errorf("%s: include cycle %s", worldDescriptor, strings.Join(cycle, " -> "))
+		}
+	}
+
+	return append(append([]string{}, chain...), path), nil
+}
+
+// JoinNamespace prefixes name with namespace. Unnamed entities stay unnamed.
+func JoinNamespace(namespace, name string) string {
+	if namespace == "" || name == "" {
+		return name
+	}
+
+	return namespace + "." + name
+}
@@ -0,0 +1,125 @@
+package World
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSquare = `{"Type": "square", "Name": "box", "Data": "{\"Origin\": {\"X\": 0, \"Y\": 0, \"Z\": 5}, \"Height\": 1, \"Width\": 1, \"Depth\": 1}"}`
+
+func testInclude(path, namespace string) string {
+	return `{"Type": "include", "Data": "{\"Path\": \"` + path + `\", \"Namespace\": \"` + namespace + `\"}"}`
+}
+
+func writeDescriptors(t *testing.T, files map[string][]string) string {
+	dir := t.TempDir()
+	for name, objects := range files {
+		data := `{"FileObjects": [` + strings.Join(objects, ", ") + `]}`
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(data), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestIncludeNamespaces(t *testing.T) {
+	dir := writeDescriptors(t, map[string][]string{
+		"main.json":    {testSquare, testInclude("parts/a.json", "a"), testInclude("parts/a.json", "")},
+		"parts/a.json": {testSquare, testInclude("b.json", "b")},
+		"parts/b.json": {testSquare},
+	})
+
+	w := NewWorld()
+	err := w.Build(filepath.Join(dir, "main.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, e := range w.Entities {
+		names = append(names, e.Name)
+	}
+	want := []string{"box", "a.box", "a.b.box", "box", "b.box"}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Errorf("entity names %v, want %v", names, want)
+	}
+	if len(w.Files()) != 5 {
+		t.Errorf("%d files, want 5", len(w.Files()))
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string][]string
+		err   string
+	}{
+		{"self", map[string][]string{
+			"main.json": {testInclude("main.json", "")},
+		}, "include cycle"},
+		{"cycle", map[string][]string{
+			"main.json": {testInclude("a.json", "")},
+			"a.json":    {testInclude("b.json", "")},
+			"b.json":    {testInclude("a.json", "")},
+		}, "include cycle"},
+		{"no path", map[string][]string{
+			"main.json": {testInclude("", "a")},
+		}, "include object without path"},
+		{"missing file", map[string][]string{
+			"main.json": {testInclude("missing.json", "")},
+		}, "missing.json"},
+	}
+
+	for _, c := range cases {
+		dir := writeDescriptors(t, c.files)
+		err := NewWorld().Build(filepath.Join(dir, "main.json"))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: error %v, want %q", c.name, err, c.err)
+		}
+	}
+}
+
+// The watcher retries a failed build when a file it read changes, so the
+// missing file has to be among them.
+func TestFailedBuildFiles(t *testing.T) {
+	dir := writeDescriptors(t, map[string][]string{
+		"main.json": {testSquare, testInclude("missing.json", "")},
+	})
+
+	world := NewWorld()
+	err := world.Build(filepath.Join(dir, "main.json"))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	files := strings.Join(world.Files(), ",")
+	if !strings.Contains(files, filepath.Join(dir, "missing.json")) {
+		t.Errorf("missing file not among %s", files)
+	}
+}
+
+func TestJoinNamespace(t *testing.T) {
+	cases := []struct {
+		namespace, name, want string
+	}{
+		{"", "box", "box"},
+		{"a", "box", "a.box"},
+		{"a.b", "box", "a.b.box"},
+		{"a", "", ""},
+	}
+
+	for _, c := range cases {
+		if got := JoinNamespace(c.namespace, c.name); got != c.want {
+			t.Errorf("JoinNamespace(%q, %q) = %q, want %q", c.namespace, c.name, got, c.want)
+		}
+	}
+}
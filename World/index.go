@@ -0,0 +1,146 @@
+package World
+
+import "strconv"
+
+// The world keeps a BVH over the bounding boxes of its entities and every
+// entity keeps a BVH over its faces. Both are rebuilt lazily on the first
+// query after an edit.
+
+// Hit describes the closest face crossed by a ray.
+type Hit struct {
+	Entity   int
+	Face     int
+	Point    Origin
+	Distance float32
+}
+
+// AddEntity appends the entity to the world and returns its index. The points
+// of the entity become the geometry its Transform is applied to.
+func (w *World) AddEntity(entity Entity) int {
+	if entity.base == nil {
+		entity.base = append([]Point{}, entity.Points...)
+		entity.Transform = IdentityTransform()
+	}
+
+	w.Entities = append(w.Entities, entity)
+	w.indexDirty = true
+
+	return len(w.Entities) - 1
+}
+
+// InsertEntity puts a previously removed entity back at index k.
+func (w *World) InsertEntity(k int, entity Entity) {
+	w.Entities = append(w.Entities, Entity{})
+	copy(w.Entities[k+1:], w.Entities[k:])
+	w.Entities[k] = entity
+	w.indexDirty = true
+}
+
+func (w *World) RemoveEntity(k int) {
+	w.Entities = append(w.Entities[:k], w.Entities[k+1:]...)
+	w.indexDirty = true
+}
+
+// UnusedName returns prefix followed by the lowest number, starting at 1, which
+// no entity is named with.
+func (w *World) UnusedName(prefix string) string {
+	used := map[string]bool{}
+	for _, entity := range w.Entities {
+		used[entity.Name] = true
+	}
+
+	name := ""
+	for k := 1; name == "" || used[name]; k++ {
+		name = prefix + strconv.Itoa(k)
+	}
+
+	return name
+}
+
+// UpdateEntity has to be called after the points of an entity were changed in
+// place.
+func (w *World) UpdateEntity(k int) {
+	w.Entities[k].UpdateBounds()
+	w.indexDirty = true
+}
+
+// QueryEntities calls visit with the index of every entity whose bounding box
+// overlaps the volume until visit returns false.
+func (w *World) QueryEntities(volume Volume, visit func(entity int) bool) bool {
+	if w.indexDirty || w.index == nil {
+		boxes := make([]AABB, len(w.Entities))
+		for k := range w.Entities {
+			boxes[k] = w.Entities[k].Box
+		}
+		w.index = BuildBVH(boxes)
+		w.indexDirty = false
+	}
+
+	return w.index.Query(volume, visit)
+}
+
+// Query calls visit with every face whose bounding box overlaps the volume
+// until visit returns false.
+func (w *World) Query(volume Volume, visit func(entity, face int) bool) bool {
+	return w.QueryEntities(volume, func(entity int) bool {
+		return w.Entities[entity].FaceIndex().Query(volume, func(face int) bool {
+			return visit(entity, face)
+		})
+	})
+}
+
+// Raycast returns the closest visible face hit by the ray.
+func (w *World) Raycast(ray Ray) (Hit, bool) {
+	hit := Hit{Entity: -1, Face: -1}
+	w.Query(ray, func(entity, face int) bool {
+		if !w.Entities[entity].Visible {
+			return true
+		}
+		if t, ok := ray.IntersectFace(&w.Entities[entity], face); ok && (hit.Entity == -1 || t < hit.Distance) {
+			hit = Hit{Entity: entity, Face: face, Distance: t}
+		}
+		return true
+	})
+
+	if hit.Entity == -1 {
+		return hit, false
+	}
+
+	hit.Point = ray.At(hit.Distance)
+	return hit, true
+}
+
+// Collides reports whether any face of a visible entity overlaps the box.
+func (w *World) Collides(box AABB) bool {
+	return !w.Query(box, func(entity, face int) bool {
+		return !w.Entities[entity].Visible
+	})
+}
+
+// FaceIndex returns the BVH over the faces of the entity.
+func (e *Entity) FaceIndex() *BVH {
+	if e.faceIndex == nil {
+		boxes := make([]AABB, len(e.Faces))
+		for k := range e.Faces {
+			boxes[k] = e.FaceBox(k)
+		}
+		e.faceIndex = BuildBVH(boxes)
+	}
+
+	return e.faceIndex
+}
+
+func (e *Entity) FaceBox(face int) AABB {
+	f := e.Faces[face]
+	box := AABB{}
+	for k, p := range []int{f.P1, f.P2, f.P3} {
+		point := Origin{X: e.Points[p].X, Y: e.Points[p].Y, Z: e.Points[p].Z}
+		if k == 0 {
+			box = AABB{Min: point, Max: point}
+		} else {
+			box = box.Union(AABB{Min: point, Max: point})
+		}
+	}
+
+	return box
+}
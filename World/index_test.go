@@ -0,0 +1,25 @@
+package World
+
+import "testing"
+
+func TestUnusedName(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{nil, "box1"},
+		{[]string{"box1", "box2"}, "box3"},
+		{[]string{"box2", "a.box1"}, "box1"},
+		{[]string{"box1", "box3"}, "box2"},
+	}
+
+	for _, test := range tests {
+		world := NewWorld()
+		for _, name := range test.names {
+			world.AddEntity(Entity{Name: name})
+		}
+		if got := world.UnusedName("box"); got != test.want {
+			t.Errorf("%v: got %q, want %q", test.names, got, test.want)
+		}
+	}
+}
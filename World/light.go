@@ -0,0 +1,135 @@
+package World
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	LightPoint       = "point"
+	LightDirectional = "directional"
+	LightSpot        = "spot"
+)
+
+// Light is the data of a "light" object. A missing type means a point light,
+// a missing color white and a missing intensity 1. Directional lights only use
+// Direction, spot lights shine along Direction within Cutoff degrees of it,
+// fading towards the edge of the cone with the Falloff exponent.
+type Light struct {
+	Name        string `json:"-"`
+	Type        string `json:",omitempty"`
+	Position    Origin
+	Direction   Origin
+	Cutoff      float32 `json:",omitempty"`
+	Falloff     float32 `json:",omitempty"`
+	Attenuation Attenuation
+	Color       Color
+	Intensity   float32
+}
+
+// Attenuation divides the light intensity by Constant + Linear*d +
+// Quadratic*d*d at distance d. It is ignored when all terms are zero.
+type Attenuation struct {
+	Constant  float32 `json:",omitempty"`
+	Linear    float32 `json:",omitempty"`
+	Quadratic float32 `json:",omitempty"`
+}
+
+// DefaultLight is a white point light of intensity 1. Lights are decoded into
+// it, so that a color or intensity left out keeps the default while black or
+// 0 given explicitly stay.
+func DefaultLight() Light {
+	return Light{Type: LightPoint, Color: Color{R: 1, G: 1, B: 1}, Intensity: 1}
+}
+
+func (f *FileObject) parseLight(world *World) error {
+	light := DefaultLight()
+	err := json.Unmarshal([]byte(f.Data), &light)
+	if err != nil {
+		log.Println("Error parsing light object data:", err.Error())
+		return err
+	}
+
+	err = light.SetDefaults()
+	if err != nil {
+		log.Println("Error parsing light object data:", err.Error())
+		return err
+	}
+
+	world.AddLight(light)
+	return nil
+}
+
+func (w *World) AddLight(light Light) {
+	light.SetDefaults()
+	w.Lights = append(w.Lights, light)
+}
+
+// SetDefaults fills in the missing type, direction, which points down for
+// directional and spot lights, and spot cutoff. Color and intensity are left
+// alone, their defaults come from DefaultLight.
+func (l *Light) SetDefaults() error {
+	if l.Type == "" {
+		l.Type = LightPoint
+	}
+	if l.Type != LightPoint && l.Type != LightDirectional && l.Type != LightSpot {
+		return errors.Errorf("unknown light type %q", l.Type)
+	}
+	if l.Type != LightPoint && l.Direction == (Origin{}) {
+		l.Direction = Origin{X: 0, Y: 1, Z: 0}
+	}
+	if l.Type == LightSpot && l.Cutoff == 0 {
+		l.Cutoff = 30
+	}
+
+	return nil
+}
+
+func (a Attenuation) Factor(distance float32) float32 {
+	d := a.Constant + a.Linear*distance + a.Quadratic*distance*distance
+	if d <= 0 {
+		return 1
+	}
+
+	return 1 / d
+}
+
+// Illuminate returns the normalized direction in which the light reaches point
+// and the share of the intensity which arrives there. It is false for points
+// outside of a spot cone.
+func (l Light) Illuminate(point Origin) (Origin, float32, bool) {
+	if l.Type == LightDirectional {
+		return normalize(l.Direction), 1, true
+	}
+
+	direction := Origin{X: point.X - l.Position.X, Y: point.Y - l.Position.Y, Z: point.Z - l.Position.Z}
+	distance := float32(math.Sqrt(float64(dot(direction, direction))))
+	if distance > 0 {
+		direction = Origin{X: direction.X / distance, Y: direction.Y / distance, Z: direction.Z / distance}
+	}
+	factor := l.Attenuation.Factor(distance)
+
+	if l.Type == LightSpot {
+		angle := dot(direction, normalize(l.Direction))
+		if angle < float32(math.Cos(float64(l.Cutoff)*math.Pi/180)) {
+			return direction, 0, false
+		}
+		if l.Falloff > 0 {
+			factor *= float32(math.Pow(float64(angle), float64(l.Falloff)))
+		}
+	}
+
+	return direction, factor, true
+}
+
+func normalize(v Origin) Origin {
+	length := float32(math.Sqrt(float64(dot(v, v))))
+	if length == 0 {
+		return v
+	}
+
+	return Origin{X: v.X / length, Y: v.Y / length, Z: v.Z / length}
+}
@@ -0,0 +1,58 @@
+package World
+
+import "testing"
+
+func TestParseLight(t *testing.T) {
+	white := Color{R: 1, G: 1, B: 1}
+	tests := []struct {
+		name      string
+		data      string
+		lightType string
+		color     Color
+		intensity float32
+		err       bool
+	}{
+		{name: "defaults", data: `{}`, lightType: LightPoint, color: white, intensity: 1},
+		{name: "given", data: `{"Type": "spot", "Color": {"R": 1, "G": 0.5, "B": 0}, "Intensity": 2}`, lightType: LightSpot, color: Color{R: 1, G: 0.5}, intensity: 2},
+		{name: "black", data: `{"Color": {"R": 0, "G": 0, "B": 0}}`, lightType: LightPoint, intensity: 1},
+		{name: "off", data: `{"Type": "directional", "Intensity": 0}`, lightType: LightDirectional, color: white},
+		{name: "unknown type", data: `{"Type": "area"}`, err: true},
+		{name: "malformed", data: `{"Intensity": "bright"}`, err: true},
+	}
+
+	for _, test := range tests {
+		world := NewWorld()
+		object := FileObject{Type: "light", Data: test.data}
+		err := object.ParseObject(world)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		light := world.Lights[0]
+		if light.Type != test.lightType || light.Color != test.color || light.Intensity != test.intensity {
+			t.Errorf("%s: got %+v", test.name, light)
+		}
+	}
+}
+
+func TestSetDefaultsKeepsColor(t *testing.T) {
+	light := Light{Type: LightSpot}
+	err := light.SetDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if light.Color != (Color{}) || light.Intensity != 0 {
+		t.Errorf("color or intensity changed: %+v", light)
+	}
+	if light.Direction != (Origin{Y: 1}) || light.Cutoff != 30 {
+		t.Errorf("got direction %+v cutoff %v", light.Direction, light.Cutoff)
+	}
+}
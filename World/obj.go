@@ -0,0 +1,211 @@
+package World
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Model is the data of an "obj" object. Path is a Wavefront OBJ file resolved
+// relative to the descriptor, whose points are multiplied by Scale, 1 when
+// left out, and moved to Origin. OBJ files point Y up, so Y is mirrored to
+// make it point up for the camera. Polygons are split into triangle fans and
+// texture coordinates and normals are ignored. The mtllib files become the
+// Libraries of the entity and are added to the material libraries of the
+// world, and the first usemtl material is used when the object names none.
+type Model struct {
+	Path   string
+	Origin Origin
+	Scale  float32 `json:",omitempty"`
+}
+
+type objData struct {
+	Points    []Point
+	Faces     []Face
+	Libraries []string
+	Materials []string
+}
+
+func (f *FileObject) parseModel(world *World) error {
+	model := Model{}
+	err := json.Unmarshal([]byte(f.Data), &model)
+	if err != nil {
+		log.Println("Error parsing obj object data:", err.Error())
+		return err
+	}
+
+	path := model.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(f.dir, path)
+	}
+
+	world.files = append(world.files, path)
+	obj, err := loadOBJ(path)
+	if err != nil {
+		log.Println("Error loading obj model:", err.Error())
+		return err
+	}
+
+	for k, library := range obj.Libraries {
+		if !filepath.IsAbs(library) {
+			library = filepath.Join(filepath.Dir(path), library)
+		}
+		obj.Libraries[k] = library
+		world.addMaterialLibrary(library)
+	}
+
+	world.buildModel(model, obj)
+	return nil
+}
+
+func (w *World) addMaterialLibrary(library string) {
+	for _, v := range w.MaterialLibraries {
+		if v == library {
+			return
+		}
+	}
+
+	w.files = append(w.files, library)
+	w.MaterialLibraries = append(w.MaterialLibraries, library)
+}
+
+func loadOBJ(path string) (objData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return objData{}, err
+	}
+	defer file.Close()
+
+	obj, err := parseOBJ(file)
+	if err != nil {
+		return objData{}, errors.Wrap(err, path)
+	}
+	if len(obj.Faces) == 0 {
+		return objData{}, errors.Errorf("%s: no faces", path)
+	}
+
+	return obj, nil
+}
+
+func parseOBJ(r io.Reader) (objData, error) {
+	obj := objData{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if k := strings.Index(text, "#"); k != -1 {
+			text = text[:k]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var err error
+		switch fields[0] {
+		case "v":
+			err = obj.parseVertex(fields[1:])
+		case "f":
+			err = obj.parseFace(fields[1:])
+		case "mtllib":
+			if len(fields) < 2 {
+				err = errors.New("missing library")
+			}
+			obj.Libraries = append(obj.Libraries, fields[1:]...)
+		case "usemtl":
+			name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "usemtl"))
+			obj.Materials = append(obj.Materials, name)
+		}
+		if err != nil {
+			return objData{}, errors.Wrapf(err, "line %d: %s", line, fields[0])
+		}
+	}
+
+	return obj, scanner.Err()
+}
+
+func (obj *objData) parseVertex(fields []string) error {
+	if len(fields) < 3 {
+		return errors.Errorf("expected 3 coordinates, got %d", len(fields))
+	}
+
+	var v [3]float32
+	for k := range v {
+		c, err := strconv.ParseFloat(fields[k], 32)
+		if err != nil {
+			return err
+		}
+		v[k] = float32(c)
+	}
+
+	obj.Points = append(obj.Points, Point{X: v[0], Y: v[1], Z: v[2]})
+	return nil
+}
+
+func (obj *objData) parseFace(fields []string) error {
+	if len(fields) < 3 {
+		return errors.Errorf("expected at least 3 vertices, got %d", len(fields))
+	}
+
+	indices := make([]int, len(fields))
+	for k, field := range fields {
+		// only the vertex of v/vt/vn is used
+		k1, err := strconv.Atoi(strings.SplitN(field, "/", 2)[0])
+		if err != nil {
+			return err
+		}
+		if k1 < 0 {
+			k1 += len(obj.Points) + 1
+		}
+		if k1 < 1 || k1 > len(obj.Points) {
+			return errors.Errorf("vertex %s out of range", field)
+		}
+		indices[k] = k1 - 1
+	}
+
+	for k := 1; k+1 < len(indices); k++ {
+		obj.Faces = append(obj.Faces, Face{P1: indices[0], P2: indices[k], P3: indices[k+1]})
+	}
+
+	return nil
+}
+
+func (w *World) buildModel(data Model, obj objData) {
+	scale := data.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	entity := Entity{}
+	entity.Type = "obj"
+	entity.Visible = true
+	entity.Object = FileObject{Type: "obj", Data: marshalData(data)}
+	entity.Points = make([]Point, len(obj.Points))
+	for k, p := range obj.Points {
+		entity.Points[k] = Point{
+			X: data.Origin.X + p.X*scale,
+			Y: data.Origin.Y - p.Y*scale,
+			Z: data.Origin.Z + p.Z*scale,
+		}
+	}
+
+	// mirroring Y turns the winding around
+	entity.Faces = make([]Face, len(obj.Faces))
+	for k, f := range obj.Faces {
+		entity.Faces[k] = Face{P1: f.P1, P2: f.P3, P3: f.P2}
+	}
+	if len(obj.Materials) > 0 {
+		entity.Material = obj.Materials[0]
+	}
+	entity.Libraries = obj.Libraries
+	entity.ConnectFaces()
+	entity.UpdateBounds()
+
+	w.AddEntity(entity)
+}
@@ -0,0 +1,176 @@
+package World
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOBJ(t *testing.T) {
+	tests := []struct {
+		name      string
+		obj       string
+		points    int
+		faces     []Face
+		libraries []string
+		materials []string
+		err       bool
+	}{
+		{name: "triangle", obj: "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n", points: 3, faces: []Face{{0, 1, 2}}},
+		{name: "quad fan", obj: "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nf 1 2 3 4\n", points: 4, faces: []Face{{0, 1, 2}, {0, 2, 3}}},
+		{name: "slashes", obj: "v 0 0 0\nv 1 0 0\nv 0 1 0\nvt 0 0\nvn 0 0 1\nf 1/1/1 2/1/1 3//1\n", points: 3, faces: []Face{{0, 1, 2}}},
+		{name: "negative indices", obj: "v 0 0 0\nv 1 0 0\nv 0 1 0\nf -3 -2 -1\n", points: 3, faces: []Face{{0, 1, 2}}},
+		{name: "comments", obj: "# model\nv 0 0 0 # origin\nv 1 0 0\nv 0 1 0 1\ng sides\nf 1 2 3\n", points: 3, faces: []Face{{0, 1, 2}}},
+		{
+			name:      "materials",
+			obj:       "mtllib a.mtl b.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nusemtl dark oak\nf 1 2 3\nusemtl red\nf 3 2 1\n",
+			points:    3,
+			faces:     []Face{{0, 1, 2}, {2, 1, 0}},
+			libraries: []string{"a.mtl", "b.mtl"},
+			materials: []string{"dark oak", "red"},
+		},
+		{name: "index out of range", obj: "v 0 0 0\nv 1 0 0\nf 1 2 3\n", err: true},
+		{name: "index zero", obj: "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 0 1 2\n", err: true},
+		{name: "two vertices", obj: "v 0 0 0\nv 1 0 0\nf 1 2\n", err: true},
+		{name: "short vertex", obj: "v 0 0\n", err: true},
+		{name: "bad number", obj: "v 0 zero 0\n", err: true},
+		{name: "missing library", obj: "mtllib\n", err: true},
+	}
+
+	for _, test := range tests {
+		obj, err := parseOBJ(strings.NewReader(test.obj))
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if len(obj.Points) != test.points {
+			t.Errorf("%s: got %d points, want %d", test.name, len(obj.Points), test.points)
+		}
+		if !equalFaces(obj.Faces, test.faces) {
+			t.Errorf("%s: got faces %v, want %v", test.name, obj.Faces, test.faces)
+		}
+		if strings.Join(obj.Libraries, ",") != strings.Join(test.libraries, ",") {
+			t.Errorf("%s: got libraries %v, want %v", test.name, obj.Libraries, test.libraries)
+		}
+		if strings.Join(obj.Materials, ",") != strings.Join(test.materials, ",") {
+			t.Errorf("%s: got materials %v, want %v", test.name, obj.Materials, test.materials)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err == nil {
+		err = ioutil.WriteFile(path, []byte(data), 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func equalFaces(a, b []Face) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if a[k] != b[k] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestBuildModelFacesUp(t *testing.T) {
+	// a floor triangle facing up, +Y in the OBJ file
+	obj, err := parseOBJ(strings.NewReader("v 0 1 0\nv 0 1 1\nv 1 1 0\nf 1 2 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	world := NewWorld()
+	world.buildModel(Model{Origin: Origin{X: 5}, Scale: 2}, obj)
+	entity := world.Entities[0]
+
+	if p := entity.Points[1]; p.X != 5 || p.Y != -2 || p.Z != 2 {
+		t.Errorf("got point %+v, want {5 -2 2}", p)
+	}
+
+	f := entity.Faces[0]
+	p1, p2, p3 := entity.Points[f.P1], entity.Points[f.P2], entity.Points[f.P3]
+	e1 := Origin{X: p2.X - p1.X, Y: p2.Y - p1.Y, Z: p2.Z - p1.Z}
+	e2 := Origin{X: p3.X - p1.X, Y: p3.Y - p1.Y, Z: p3.Z - p1.Z}
+	if y := e1.Z*e2.X - e1.X*e2.Z; y >= 0 {
+		t.Errorf("face normal points down, Y is %v", y)
+	}
+	if len(entity.Lines) != 3 {
+		t.Errorf("got %d lines, want 3", len(entity.Lines))
+	}
+}
+
+func TestBuildOBJ(t *testing.T) {
+	chair := `{"Type": "obj", "Name": "chair", "Data": "{\"Path\": \"models/chair.obj\"}"}`
+	table := `{"Type": "obj", "Name": "table", "Material": "oak", "Data": "{\"Path\": \"models/chair.obj\"}"}`
+	dir := writeDescriptors(t, map[string][]string{"world.json": {chair, table}})
+	writeFile(t, filepath.Join(dir, "models", "chair.obj"), "mtllib chair.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nusemtl red\nf 1 2 3\n")
+
+	world := NewWorld()
+	err := world.Build(filepath.Join(dir, "world.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(world.Entities) != 2 || world.Entities[0].Type != "obj" {
+		t.Fatalf("got %d entities", len(world.Entities))
+	}
+	if world.Entities[0].Material != "red" || world.Entities[1].Material != "oak" {
+		t.Errorf("got materials %q and %q", world.Entities[0].Material, world.Entities[1].Material)
+	}
+
+	library := filepath.Join(dir, "models", "chair.mtl")
+	if len(world.MaterialLibraries) != 1 || world.MaterialLibraries[0] != library {
+		t.Errorf("got libraries %v", world.MaterialLibraries)
+	}
+	for _, entity := range world.Entities {
+		if len(entity.Libraries) != 1 || entity.Libraries[0] != library {
+			t.Errorf("%s: got libraries %v", entity.Name, entity.Libraries)
+		}
+	}
+
+	saved := filepath.Join(dir, "saved", "world.json")
+	err = os.Mkdir(filepath.Dir(saved), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = world.Save(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := NewWorld()
+	err = reloaded.Build(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Entities) != 2 || len(reloaded.Entities[0].Faces) != 1 {
+		t.Errorf("saved world has %d entities", len(reloaded.Entities))
+	}
+}
+
+func TestLoadOBJWithoutFaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "points.obj")
+	writeFile(t, path, "v 0 0 0\n")
+
+	_, err := loadOBJ(path)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
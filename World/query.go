@@ -0,0 +1,58 @@
+package World
+
+// Returned entity pointers point into World.Entities and stay valid until
+// entities are added or removed.
+
+func (w *World) EntityByName(name string) *Entity {
+	for k := range w.Entities {
+		if w.Entities[k].Name == name {
+			return &w.Entities[k]
+		}
+	}
+
+	return nil
+}
+
+func (w *World) EntitiesByTag(tag string) []*Entity {
+	entities := []*Entity{}
+	for k := range w.Entities {
+		if w.Entities[k].HasTag(tag) {
+			entities = append(entities, &w.Entities[k])
+		}
+	}
+
+	return entities
+}
+
+// EntitiesInRegion returns entities whose bounds overlap the box spanned by
+// min and max.
+func (w *World) EntitiesInRegion(min, max Origin) []*Entity {
+	entities := []*Entity{}
+	w.QueryEntities(AABB{Min: min, Max: max}, func(entity int) bool {
+		entities = append(entities, &w.Entities[entity])
+		return true
+	})
+
+	return entities
+}
+
+// SetVisible shows or hides the named entity and reports whether it exists.
+func (w *World) SetVisible(name string, visible bool) bool {
+	entity := w.EntityByName(name)
+	if entity == nil {
+		return false
+	}
+
+	entity.Visible = visible
+	return true
+}
+
+func (e *Entity) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
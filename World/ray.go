@@ -0,0 +1,104 @@
+package World
+
+import "math"
+
+// Ray starts at Origin and extends along Direction, which does not have to be
+// normalized. Distances along the ray are measured in Direction lengths.
+type Ray struct {
+	Origin    Origin
+	Direction Origin
+}
+
+// OverlapsBox implements the slab test.
+func (r Ray) OverlapsBox(box AABB) bool {
+	tMin := float32(0)
+	tMax := float32(math.MaxFloat32)
+	for axis := 0; axis < 3; axis++ {
+		o := r.Origin.Axis(axis)
+		d := r.Direction.Axis(axis)
+		min := box.Min.Axis(axis)
+		max := box.Max.Axis(axis)
+
+		if d == 0 {
+			if o < min || o > max {
+				return false
+			}
+			continue
+		}
+
+		t1 := (min - o) / d
+		t2 := (max - o) / d
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > tMin {
+			tMin = t1
+		}
+		if t2 < tMax {
+			tMax = t2
+		}
+		if tMin > tMax {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IntersectFace returns the distance along the ray to the face of the entity
+// using the Moller-Trumbore algorithm. Both sides of the face are hit.
+func (r Ray) IntersectFace(entity *Entity, face int) (float32, bool) {
+	f := entity.Faces[face]
+	p1 := entity.Points[f.P1]
+	p2 := entity.Points[f.P2]
+	p3 := entity.Points[f.P3]
+
+	e1 := Origin{X: p2.X - p1.X, Y: p2.Y - p1.Y, Z: p2.Z - p1.Z}
+	e2 := Origin{X: p3.X - p1.X, Y: p3.Y - p1.Y, Z: p3.Z - p1.Z}
+	p := cross(r.Direction, e2)
+	det := dot(e1, p)
+	if det > -1e-9 && det < 1e-9 {
+		return 0, false
+	}
+
+	inv := 1 / det
+	s := Origin{X: r.Origin.X - p1.X, Y: r.Origin.Y - p1.Y, Z: r.Origin.Z - p1.Z}
+	u := dot(s, p) * inv
+	if u < 0 || u > 1 {
+		return 0, false
+	}
+
+	q := cross(s, e1)
+	v := dot(r.Direction, q) * inv
+	if v < 0 || u+v > 1 {
+		return 0, false
+	}
+
+	t := dot(e2, q) * inv
+	if t < 0 {
+		return 0, false
+	}
+
+	return t, true
+}
+
+// At returns the point at distance t along the ray.
+func (r Ray) At(t float32) Origin {
+	return Origin{
+		X: r.Origin.X + r.Direction.X*t,
+		Y: r.Origin.Y + r.Direction.Y*t,
+		Z: r.Origin.Z + r.Direction.Z*t,
+	}
+}
+
+func dot(a, b Origin) float32 {
+	return a.X*b.X + a.Y*b.Y + a.Z*b.Z
+}
+
+func cross(a, b Origin) Origin {
+	return Origin{
+		X: a.Y*b.Z - a.Z*b.Y,
+		Y: a.Z*b.X - a.X*b.Z,
+		Z: a.X*b.Y - a.Y*b.X,
+	}
+}
@@ -0,0 +1,123 @@
+package World
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Save writes all entities into a single world descriptor. Every entity is
+// written as the object it was built from together with its name, tags,
+// visibility and transform, so included files end up merged into one.
+func (w *World) Save(worldDescriptor string) error {
+	log.Println("Saving world to", worldDescriptor)
+
+	fileData := FileData{FileObjects: []FileObject{}}
+	for k, entity := range w.Entities {
+		object, err := entity.fileObject(filepath.Dir(worldDescriptor))
+		if err != nil {
+			log.Println("Error saving entity", k, entity.Name+":", err.Error())
+			return errors.Wrap(err, worldDescriptor)
+		}
+		fileData.FileObjects = append(fileData.FileObjects, object)
+	}
+
+	for _, light := range w.Lights {
+		fileData.FileObjects = append(fileData.FileObjects, FileObject{
+			Type: "light",
+			Name: light.Name,
+			Data: marshalData(light),
+		})
+	}
+
+	buffer, err := json.MarshalIndent(fileData, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, worldDescriptor)
+	}
+
+	err = ioutil.WriteFile(worldDescriptor, buffer, 0644)
+	if err != nil {
+		log.Println("Error writing world descriptor:", err.Error())
+		return errors.Wrap(err, worldDescriptor)
+	}
+
+	return nil
+}
+
+func (e *Entity) fileObject(dir string) (FileObject, error) {
+	object := e.Object
+	if object.Type == "" {
+		return object, errors.Errorf("%s entity was not built from a descriptor object", e.Type)
+	}
+
+	object.Name = e.Name
+	object.Tags = e.Tags
+	object.Hidden = !e.Visible
+	object.Material = e.Material
+	object.Color = e.Color
+	object.Transform = nil
+	if !e.Transform.IsIdentity() {
+		transform := e.Transform
+		object.Transform = &transform
+	}
+
+	if object.Type == "terrain" {
+		terrain := Terrain{}
+		err := json.Unmarshal([]byte(object.Data), &terrain)
+		if err != nil {
+			return object, err
+		}
+
+		terrain.Heightmap, err = rebasePath(terrain.Heightmap, object.dir, dir)
+		if err != nil {
+			return object, err
+		}
+		object.Data = marshalData(terrain)
+	} else if object.Type == "obj" {
+		model := Model{}
+		err := json.Unmarshal([]byte(object.Data), &model)
+		if err != nil {
+			return object, err
+		}
+
+		model.Path, err = rebasePath(model.Path, object.dir, dir)
+		if err != nil {
+			return object, err
+		}
+		object.Data = marshalData(model)
+	}
+
+	return object, nil
+}
+
+// rebasePath makes a path given relative to from relative to dir.
+func rebasePath(path, from, dir string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(from, path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		path = rel
+	}
+
+	return filepath.ToSlash(path), nil
+}
+
+func marshalData(data interface{}) string {
+	buffer, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		log.Println("Error serializing object data:", err.Error())
+	}
+
+	return string(buffer)
+}
@@ -0,0 +1,195 @@
+package World
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// Sphere is the data of a "sphere" object. The "uv" kind, the default, is
+// made of Rings and Segments, the "ico" kind subdivides an icosahedron
+// Subdivisions times.
+type Sphere struct {
+	Center       Origin
+	Radius       float32
+	Kind         string `json:",omitempty"`
+	Rings        int    `json:",omitempty"`
+	Segments     int    `json:",omitempty"`
+	Subdivisions int    `json:",omitempty"`
+}
+
+// Mesh is a closed triangle mesh with a normal for every point.
+type Mesh struct {
+	Points  []Point
+	Normals []Origin
+	Faces   []Face
+}
+
+func (f *FileObject) parseSphere(world *World) error {
+	sphere := Sphere{}
+	err := json.Unmarshal([]byte(f.Data), &sphere)
+	if err != nil {
+		log.Println("Error parsing sphere object data:", err.Error())
+		return err
+	}
+
+	if sphere.Kind != "" && sphere.Kind != "uv" && sphere.Kind != "ico" {
+		err = errors.Errorf("unknown sphere kind %q", sphere.Kind)
+		log.Println("Error parsing sphere object data:", err.Error())
+		return err
+	}
+
+	world.BuildSphere(sphere)
+	return nil
+}
+
+// Mesh makes the mesh of the sphere, an unknown kind being logged and made a
+// UV sphere.
+func (s Sphere) Mesh() Mesh {
+	switch s.Kind {
+	case "ico":
+		return Icosphere(s.Center, s.Radius, s.Subdivisions)
+	case "", "uv":
+	default:
+		log.Println("Unknown sphere kind, making a uv sphere:", s.Kind)
+	}
+
+	return UVSphere(s.Center, s.Radius, s.Rings, s.Segments)
+}
+
+func (w *World) BuildSphere(data Sphere) {
+	mesh := data.Mesh()
+
+	entity := Entity{}
+	entity.Type = "sphere"
+	entity.Visible = true
+	entity.Object = FileObject{Type: "sphere", Data: marshalData(data)}
+	entity.Points = mesh.Points
+	entity.Faces = mesh.Faces
+	entity.ConnectFaces()
+	entity.UpdateBounds()
+
+	w.AddEntity(entity)
+}
+
+// UVSphere makes a sphere out of rings of latitude and segments of longitude,
+// with a single point at each pole. It uses at least 2 rings and 3 segments.
+func UVSphere(center Origin, radius float32, rings, segments int) Mesh {
+	if rings < 2 {
+		rings = 2
+	}
+	if segments < 3 {
+		segments = 3
+	}
+
+	mesh := Mesh{}
+	add := func(n Origin) int {
+		mesh.Normals = append(mesh.Normals, n)
+		mesh.Points = append(mesh.Points, Point{X: center.X + n.X*radius, Y: center.Y + n.Y*radius, Z: center.Z + n.Z*radius, ConnectedTo: []int{}})
+		return len(mesh.Points) - 1
+	}
+
+	top := add(Origin{X: 0, Y: -1, Z: 0})
+	for r := 1; r < rings; r++ {
+		theta := math.Pi * float64(r) / float64(rings)
+		for s := 0; s < segments; s++ {
+			phi := 2 * math.Pi * float64(s) / float64(segments)
+			add(Origin{
+				X: float32(math.Sin(theta) * math.Cos(phi)),
+				Y: float32(-math.Cos(theta)),
+				Z: float32(math.Sin(theta) * math.Sin(phi)),
+			})
+		}
+	}
+	bottom := add(Origin{X: 0, Y: 1, Z: 0})
+
+	ring := func(r, s int) int {
+		return 1 + (r-1)*segments + s%segments
+	}
+	for s := 0; s < segments; s++ {
+		mesh.addFace(center, top, ring(1, s), ring(1, s+1))
+		mesh.addFace(center, bottom, ring(rings-1, s+1), ring(rings-1, s))
+	}
+	for r := 1; r < rings-1; r++ {
+		for s := 0; s < segments; s++ {
+			mesh.addFace(center, ring(r, s), ring(r+1, s), ring(r+1, s+1))
+			mesh.addFace(center, ring(r, s), ring(r+1, s+1), ring(r, s+1))
+		}
+	}
+
+	return mesh
+}
+
+// Icosphere subdivides every face of an icosahedron into four, pushing the
+// new points onto the sphere, which spreads the points more evenly than
+// UVSphere.
+func Icosphere(center Origin, radius float32, subdivisions int) Mesh {
+	t := float32((1 + math.Sqrt(5)) / 2)
+	directions := []Origin{
+		{-1, t, 0}, {1, t, 0}, {-1, -t, 0}, {1, -t, 0},
+		{0, -1, t}, {0, 1, t}, {0, -1, -t}, {0, 1, -t},
+		{t, 0, -1}, {t, 0, 1}, {-t, 0, -1}, {-t, 0, 1},
+	}
+	faces := []Face{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+		{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+		{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+	}
+	for k := range directions {
+		directions[k] = normalize(directions[k])
+	}
+
+	for i := 0; i < subdivisions; i++ {
+		middles := map[[2]int]int{}
+		middle := func(a, b int) int {
+			if a > b {
+				a, b = b, a
+			}
+			if k, ok := middles[[2]int{a, b}]; ok {
+				return k
+			}
+			directions = append(directions, normalize(Origin{
+				X: directions[a].X + directions[b].X,
+				Y: directions[a].Y + directions[b].Y,
+				Z: directions[a].Z + directions[b].Z,
+			}))
+			middles[[2]int{a, b}] = len(directions) - 1
+			return len(directions) - 1
+		}
+
+		subdivided := make([]Face, 0, 4*len(faces))
+		for _, f := range faces {
+			a := middle(f.P1, f.P2)
+			b := middle(f.P2, f.P3)
+			c := middle(f.P3, f.P1)
+			subdivided = append(subdivided, Face{f.P1, a, c}, Face{f.P2, b, a}, Face{f.P3, c, b}, Face{a, b, c})
+		}
+		faces = subdivided
+	}
+
+	mesh := Mesh{Normals: directions}
+	for _, n := range directions {
+		mesh.Points = append(mesh.Points, Point{X: center.X + n.X*radius, Y: center.Y + n.Y*radius, Z: center.Z + n.Z*radius, ConnectedTo: []int{}})
+	}
+	for _, f := range faces {
+		mesh.addFace(center, f.P1, f.P2, f.P3)
+	}
+
+	return mesh
+}
+
+// addFace appends a face wound so that it faces away from center.
+func (m *Mesh) addFace(center Origin, p1, p2, p3 int) {
+	a := m.Points[p1]
+	b := m.Points[p2]
+	c := m.Points[p3]
+	n := cross(Origin{X: b.X - a.X, Y: b.Y - a.Y, Z: b.Z - a.Z}, Origin{X: c.X - a.X, Y: c.Y - a.Y, Z: c.Z - a.Z})
+	if dot(n, Origin{X: a.X - center.X, Y: a.Y - center.Y, Z: a.Z - center.Z}) < 0 {
+		p2, p3 = p3, p2
+	}
+
+	m.Faces = append(m.Faces, Face{P1: p1, P2: p2, P3: p3})
+}
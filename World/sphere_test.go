@@ -0,0 +1,37 @@
+package World
+
+import "testing"
+
+func TestParseSphereKind(t *testing.T) {
+	// 4 rings of 8 segments are two caps of 8 triangles and two bands of 16
+	tests := []struct {
+		kind  string
+		faces int
+		err   bool
+	}{
+		{kind: "", faces: 48},
+		{kind: "uv", faces: 48},
+		{kind: "ico", faces: 20 * 4},
+		{kind: "disc", err: true},
+		{kind: "cube", err: true},
+	}
+
+	for _, test := range tests {
+		world := NewWorld()
+		object := FileObject{Type: "sphere", Data: `{"Radius": 1, "Kind": "` + test.kind + `", "Rings": 4, "Segments": 8, "Subdivisions": 1}`}
+		err := object.ParseObject(world)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", test.kind)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.kind, err)
+			continue
+		}
+		if faces := len(world.Entities[0].Faces); faces != test.faces {
+			t.Errorf("%q: got %d faces, want %d", test.kind, faces, test.faces)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package World
+
+import (
+	"bufio"
+	"encoding/json"
+	"image"
+	"image/color"
+	_ "image/png"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Terrain is the data of a "terrain" object. Heightmap is a PNG or PGM file
+// resolved relative to the descriptor. Heights grow towards negative Y, which
+// is up for the camera.
+type Terrain struct {
+	Origin        Origin
+	Heightmap     string
+	CellSize      float32
+	HeightScale   float32
+	Decimate      bool
+	FlatTolerance float32
+}
+
+// maxHeightmapSide limits the size a PGM header may claim.
+const maxHeightmapSide = 1 << 14
+
+type Heightmap struct {
+	Width  int
+	Height int
+	Values []float32
+}
+
+func (h *Heightmap) At(x, y int) float32 {
+	return h.Values[y*h.Width+x]
+}
+
+func LoadHeightmap(path string) (Heightmap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Heightmap{}, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	magic, err := reader.Peek(2)
+	if err != nil {
+		return Heightmap{}, errors.Wrap(err, path)
+	}
+
+	var heightmap Heightmap
+	if string(magic) == "P2" || string(magic) == "P5" {
+		heightmap, err = readPGM(reader)
+	} else {
+		heightmap, err = readImage(reader)
+	}
+	if err != nil {
+		return Heightmap{}, errors.Wrap(err, path)
+	}
+
+	if heightmap.Width < 2 || heightmap.Height < 2 {
+		return Heightmap{}, errors.Errorf("%s: heightmap must be at least 2x2 pixels", path)
+	}
+
+	return heightmap, nil
+}
+
+func readImage(r io.Reader) (Heightmap, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return Heightmap{}, err
+	}
+
+	bounds := img.Bounds()
+	heightmap := Heightmap{Width: bounds.Dx(), Height: bounds.Dy()}
+	heightmap.Values = make([]float32, heightmap.Width*heightmap.Height)
+	for y := 0; y < heightmap.Height; y++ {
+		for x := 0; x < heightmap.Width; x++ {
+			gray := color.Gray16Model.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray16)
+			heightmap.Values[y*heightmap.Width+x] = float32(gray.Y) / 65535
+		}
+	}
+
+	return heightmap, nil
+}
+
+func readPGM(r *bufio.Reader) (Heightmap, error) {
+	header := make([]int, 0, 3)
+	magic, err := readPGMToken(r)
+	if err != nil {
+		return Heightmap{}, err
+	}
+
+	for len(header) < 3 {
+		token, err := readPGMToken(r)
+		if err != nil {
+			return Heightmap{}, err
+		}
+		value, err := strconv.Atoi(token)
+		if err != nil || value <= 0 {
+			return Heightmap{}, errors.Errorf("invalid PGM header value %q", token)
+		}
+		header = append(header, value)
+	}
+
+	if header[0] > maxHeightmapSide || header[1] > maxHeightmapSide {
+		return Heightmap{}, errors.Errorf("PGM size %dx%d is over %d pixels a side", header[0], header[1], maxHeightmapSide)
+	}
+	if header[2] > 65535 {
+		return Heightmap{}, errors.Errorf("invalid PGM maximum value %d", header[2])
+	}
+
+	heightmap := Heightmap{Width: header[0], Height: header[1]}
+	maxValue := float32(header[2])
+	// the values grow as samples are read, so that a header claiming more
+	// than the file holds fails on the missing data instead of allocating it
+	samples := heightmap.Width * heightmap.Height
+	capacity := samples
+	if capacity > 1<<16 {
+		capacity = 1 << 16
+	}
+	heightmap.Values = make([]float32, 0, capacity)
+
+	for len(heightmap.Values) < samples {
+		var value int
+		if magic == "P2" {
+			token, err := readPGMToken(r)
+			if err != nil {
+				return Heightmap{}, err
+			}
+			value, err = strconv.Atoi(token)
+			if err != nil {
+				return Heightmap{}, errors.Errorf("invalid PGM sample %q", token)
+			}
+		} else if header[2] < 256 {
+			b, err := r.ReadByte()
+			if err != nil {
+				return Heightmap{}, err
+			}
+			value = int(b)
+		} else {
+			hi, err := r.ReadByte()
+			if err != nil {
+				return Heightmap{}, err
+			}
+			lo, err := r.ReadByte()
+			if err != nil {
+				return Heightmap{}, err
+			}
+			value = int(hi)<<8 | int(lo)
+		}
+
+		if value > header[2] {
+			return Heightmap{}, errors.Errorf("PGM sample %d is over the maximum value %d", value, header[2])
+		}
+
+		heightmap.Values = append(heightmap.Values, float32(value)/maxValue)
+	}
+
+	return heightmap, nil
+}
+
+// readPGMToken reads one whitespace separated token skipping # comments. The
+// single whitespace byte following the token is consumed, as required before
+// P5 binary data.
+func readPGMToken(r *bufio.Reader) (string, error) {
+	token := strings.Builder{}
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && token.Len() > 0 {
+				return token.String(), nil
+			}
+			return "", err
+		}
+
+		if b == '#' && token.Len() == 0 {
+			_, err = r.ReadString('\n')
+			if err != nil {
+				return "", err
+			}
+		} else if b == ' ' || b == '\t' || b == '\n' || b == '\r' {
+			if token.Len() > 0 {
+				return token.String(), nil
+			}
+		} else {
+			token.WriteByte(b)
+		}
+	}
+}
+
+func (w *World) BuildTerrain(data Terrain, heightmap Heightmap) {
+	entity := Entity{}
+	entity.Type = "terrain"
+	entity.Visible = true
+	entity.Points = []Point{}
+	entity.Lines = []Line{}
+	entity.Faces = []Face{}
+
+	if data.CellSize <= 0 {
+		data.CellSize = 1
+	}
+	if data.HeightScale == 0 {
+		data.HeightScale = 1
+	}
+
+	indices := map[int]int{}
+	point := func(x, y int) int {
+		if k, ok := indices[y*heightmap.Width+x]; ok {
+			return k
+		}
+		entity.Points = append(entity.Points, Point{
+			X:           data.Origin.X + float32(x)*data.CellSize,
+			Y:           data.Origin.Y - heightmap.At(x, y)*data.HeightScale,
+			Z:           data.Origin.Z + float32(y)*data.CellSize,
+			ConnectedTo: []int{},
+		})
+		indices[y*heightmap.Width+x] = len(entity.Points) - 1
+		return len(entity.Points) - 1
+	}
+
+	face := func(f [3]terrainNode) {
+		entity.Faces = append(entity.Faces, Face{P1: point(f[0].X, f[0].Y), P2: point(f[1].X, f[1].Y), P3: point(f[2].X, f[2].Y)})
+	}
+
+	blocks := []terrainBlock{}
+	if data.Decimate {
+		size := 1
+		for size < heightmap.Width-1 || size < heightmap.Height-1 {
+			size *= 2
+		}
+		decimateTerrain(heightmap, 0, 0, size, data.FlatTolerance, func(x, y, size int) {
+			block := terrainBlock{X: x, Y: y, X2: x + size, Y2: y + size}
+			if block.X2 > heightmap.Width-1 {
+				block.X2 = heightmap.Width - 1
+			}
+			if block.Y2 > heightmap.Height-1 {
+				block.Y2 = heightmap.Height - 1
+			}
+			blocks = append(blocks, block)
+		})
+	} else {
+		for y := 0; y < heightmap.Height-1; y++ {
+			for x := 0; x < heightmap.Width-1; x++ {
+				blocks = append(blocks, terrainBlock{X: x, Y: y, X2: x + 1, Y2: y + 1})
+			}
+		}
+	}
+
+	corners := map[terrainNode]bool{}
+	for _, b := range blocks {
+		corners[terrainNode{b.X, b.Y}] = true
+		corners[terrainNode{b.X2, b.Y}] = true
+		corners[terrainNode{b.X2, b.Y2}] = true
+		corners[terrainNode{b.X, b.Y2}] = true
+	}
+	for _, b := range blocks {
+		for _, f := range b.faces(corners) {
+			face(f)
+		}
+	}
+
+	entity.ConnectFaces()
+	entity.UpdateBounds()
+
+	w.AddEntity(entity)
+}
+
+// terrainNode is a pixel of the heightmap.
+type terrainNode struct {
+	X, Y int
+}
+
+// terrainBlock is a rectangle of heightmap cells drawn as one piece.
+type terrainBlock struct {
+	X, Y, X2, Y2 int
+}
+
+// faces triangulates the block through every corner of the other blocks on its
+// edges, so that it leaves no T-junctions, or cracks when heights are
+// flattened, where it borders smaller blocks. Faces wind like the cell
+// (X, Y), (X2, Y), (X2, Y2), which faces up.
+func (b terrainBlock) faces(corners map[terrainNode]bool) [][3]terrainNode {
+	// the corners along the edge from x, y taking n steps of dx, dy
+	edge := func(x, y, dx, dy, n int) []terrainNode {
+		nodes := []terrainNode{}
+		for k := 0; k < n; k++ {
+			if corners[terrainNode{x + k*dx, y + k*dy}] {
+				nodes = append(nodes, terrainNode{x + k*dx, y + k*dy})
+			}
+		}
+		return nodes
+	}
+
+	width := b.X2 - b.X
+	height := b.Y2 - b.Y
+	if width == 1 {
+		left := edge(b.X, b.Y, 0, 1, height+1)
+		right := edge(b.X2, b.Y, 0, 1, height+1)
+		return zipTerrain(left, right, func(n terrainNode) int { return n.Y })
+	}
+	if height == 1 {
+		bottom := edge(b.X, b.Y2, 1, 0, width+1)
+		top := edge(b.X, b.Y, 1, 0, width+1)
+		return zipTerrain(bottom, top, func(n terrainNode) int { return n.X })
+	}
+
+	outline := edge(b.X, b.Y, 1, 0, width)
+	outline = append(outline, edge(b.X2, b.Y, 0, 1, height)...)
+	outline = append(outline, edge(b.X2, b.Y2, -1, 0, width)...)
+	outline = append(outline, edge(b.X, b.Y2, 0, -1, height)...)
+	if len(outline) == 4 {
+		return [][3]terrainNode{{outline[0], outline[2], outline[3]}, {outline[0], outline[1], outline[2]}}
+	}
+
+	// a fan around the middle, which is inside the block as it is at least
+	// two cells wide and high
+	middle := terrainNode{b.X + width/2, b.Y + height/2}
+	faces := make([][3]terrainNode, len(outline))
+	for k := range outline {
+		faces[k] = [3]terrainNode{middle, outline[k], outline[(k+1)%len(outline)]}
+	}
+
+	return faces
+}
+
+// zipTerrain joins two opposite edges of a block one cell thick with a strip of
+// triangles, always stepping along the edge whose next node comes first.
+func zipTerrain(a, b []terrainNode, along func(terrainNode) int) [][3]terrainNode {
+	faces := [][3]terrainNode{}
+	i, j := 0, 0
+	for i+1 < len(a) || j+1 < len(b) {
+		if j+1 < len(b) && (i+1 == len(a) || along(b[j+1]) <= along(a[i+1])) {
+			faces = append(faces, [3]terrainNode{a[i], b[j], b[j+1]})
+			j++
+		} else {
+			faces = append(faces, [3]terrainNode{a[i], b[j], a[i+1]})
+			i++
+		}
+	}
+
+	return faces
+}
+
+// decimateTerrain splits the heightmap into square blocks and emits a single
+// cell for every block whose heights differ by no more than tolerance.
+func decimateTerrain(heightmap Heightmap, x, y, size int, tolerance float32, cell func(x, y, size int)) {
+	if x >= heightmap.Width-1 || y >= heightmap.Height-1 {
+		return
+	}
+
+	if size == 1 || isFlat(heightmap, x, y, size, tolerance) {
+		cell(x, y, size)
+		return
+	}
+
+	half := size / 2
+	decimateTerrain(heightmap, x, y, half, tolerance, cell)
+	decimateTerrain(heightmap, x+half, y, half, tolerance, cell)
+	decimateTerrain(heightmap, x, y+half, half, tolerance, cell)
+	decimateTerrain(heightmap, x+half, y+half, half, tolerance, cell)
+}
+
+func isFlat(heightmap Heightmap, x, y, size int, tolerance float32) bool {
+	min := heightmap.At(x, y)
+	max := min
+	for j := y; j <= y+size && j < heightmap.Height; j++ {
+		for i := x; i <= x+size && i < heightmap.Width; i++ {
+			v := heightmap.At(i, j)
+			if v < min {
+				min = v
+			} else if v > max {
+				max = v
+			}
+		}
+	}
+
+	return max-min <= tolerance
+}
+
+func (f *FileObject) parseTerrain(world *World) error {
+	terrain := Terrain{}
+	err := json.Unmarshal([]byte(f.Data), &terrain)
+	if err != nil {
+		log.Println("Error parsing terrain object data:", err.Error())
+		return err
+	}
+
+	path := terrain.Heightmap
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(f.dir, path)
+	}
+
+	world.files = append(world.files, path)
+	heightmap, err := LoadHeightmap(path)
+	if err != nil {
+		log.Println("Error loading terrain heightmap:", err.Error())
+		return err
+	}
+
+	world.BuildTerrain(terrain, heightmap)
+	return nil
+}
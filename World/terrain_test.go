@@ -0,0 +1,136 @@
+package World
+
+import (
+	"bufio"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlatTerrainFacesUp(t *testing.T) {
+	heightmap := Heightmap{Width: 3, Height: 3, Values: make([]float32, 9)}
+	for _, decimate := range []bool{false, true} {
+		w := NewWorld()
+		w.BuildTerrain(Terrain{Decimate: decimate}, heightmap)
+		entity := &w.Entities[0]
+
+		for _, f := range entity.Faces {
+			a := entity.Points[f.P1]
+			b := entity.Points[f.P2]
+			c := entity.Points[f.P3]
+			n := cross(Origin{X: b.X - a.X, Y: b.Y - a.Y, Z: b.Z - a.Z}, Origin{X: c.X - a.X, Y: c.Y - a.Y, Z: c.Z - a.Z})
+			if n.X != 0 || n.Y >= 0 || n.Z != 0 {
+				t.Errorf("decimate %t: face %v normal %v, want -Y", decimate, f, n)
+			}
+		}
+
+		for k, n := range entity.VertexNormals() {
+			if n != (Origin{X: 0, Y: -1, Z: 0}) {
+				t.Errorf("decimate %t: point %d normal %v, want {0 -1 0}", decimate, k, n)
+			}
+		}
+	}
+}
+
+func TestDecimatedTerrainIsClosed(t *testing.T) {
+	// a bump on a nearly flat map, which is not a power of two plus one wide,
+	// leaves large blocks next to single cells
+	heightmap := Heightmap{Width: 10, Height: 7, Values: make([]float32, 70)}
+	for k := range heightmap.Values {
+		heightmap.Values[k] = float32(k%3) * 0.01
+	}
+	heightmap.Values[2*10+2] = 1
+
+	w := NewWorld()
+	w.BuildTerrain(Terrain{Decimate: true, FlatTolerance: 0.05}, heightmap)
+	entity := &w.Entities[0]
+	if len(entity.Faces) >= 2*9*6 {
+		t.Errorf("%d faces, want fewer than the %d of the full map", len(entity.Faces), 2*9*6)
+	}
+
+	// every edge inside the map must be shared by two faces running it in
+	// opposite directions, a T-junction leaves the long edge unshared
+	edges := map[[2]int]int{}
+	area := float32(0)
+	for _, f := range entity.Faces {
+		a := entity.Points[f.P1]
+		b := entity.Points[f.P2]
+		c := entity.Points[f.P3]
+		n := cross(Origin{X: b.X - a.X, Z: b.Z - a.Z}, Origin{X: c.X - a.X, Z: c.Z - a.Z})
+		if n.Y >= 0 {
+			t.Errorf("face %v faces down or is degenerate", f)
+		}
+		area -= n.Y / 2
+
+		for _, e := range [][2]int{{f.P1, f.P2}, {f.P2, f.P3}, {f.P3, f.P1}} {
+			edges[e]++
+		}
+	}
+	if area != 9*6 {
+		t.Errorf("faces cover %v, want %v", area, 9*6)
+	}
+
+	for e, count := range edges {
+		a := entity.Points[e[0]]
+		b := entity.Points[e[1]]
+		onBorder := a.X == b.X && (a.X == 0 || a.X == 9) || a.Z == b.Z && (a.Z == 0 || a.Z == 6)
+		if count != 1 || (edges[[2]int{e[1], e[0]}] != 1 && !onBorder) {
+			t.Errorf("edge %v to %v is used %d times and %d times reversed", a, b, count, edges[[2]int{e[1], e[0]}])
+		}
+	}
+}
+
+func TestReadPGM(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   string
+		values []float32
+		err    bool
+	}{
+		{"ascii", "P2\n# comment\n2 2\n# another\n4\n0 1\n2 4\n", []float32{0, 0.25, 0.5, 1}, false},
+		{"binary", "P5 2 1 255\n\x00\xff", []float32{0, 1}, false},
+		{"binary 16 bit", "P5 2 1 65535\n\x00\x00\xff\xff", []float32{0, 1}, false},
+		{"no trailing newline", "P2 2 1 2 1 2", []float32{0.5, 1}, false},
+		{"bad header", "P2 2 x 255\n", nil, true},
+		{"zero width", "P2 0 2 255\n", nil, true},
+		{"bad sample", "P2 2 1 255 1 y", nil, true},
+		{"truncated", "P5 2 2 255\n\x00", nil, true},
+		{"truncated huge", "P5 16384 16384 255\n\x00", nil, true},
+		{"too wide", "P5 16385 2 255\n\x00", nil, true},
+		{"maximum value over 16 bits", "P2 2 1 65536 0 1", nil, true},
+		{"sample over maximum", "P2 2 1 4 1 5", nil, true},
+		{"binary sample over maximum", "P5 2 1 4\n\x00\x05", nil, true},
+	}
+
+	for _, c := range cases {
+		heightmap, err := readPGM(bufio.NewReader(strings.NewReader(c.data)))
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: no error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(heightmap.Values, c.values) {
+			t.Errorf("%s: values %v, want %v", c.name, heightmap.Values, c.values)
+		}
+	}
+}
+
+func TestLoadHeightmapTooSmall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "small.pgm")
+	err := ioutil.WriteFile(path, []byte("P2 1 1 255 7"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadHeightmap(path)
+	if err == nil || !strings.Contains(err.Error(), "at least 2x2") {
+		t.Errorf("error %v, want too small", err)
+	}
+}
@@ -0,0 +1,67 @@
+package World
+
+import "math"
+
+// Transform places an entity relative to the geometry it was built with.
+// Points are scaled and rotated around the center of that geometry and then
+// translated. Rotation holds degrees around the X, Y and Z axis applied in
+// that order, like Camera.RotateVector3D.
+type Transform struct {
+	Translation Origin
+	Rotation    Origin
+	Scale       Origin
+}
+
+func IdentityTransform() Transform {
+	return Transform{Scale: Origin{X: 1, Y: 1, Z: 1}}
+}
+
+func (t Transform) IsIdentity() bool {
+	return t == IdentityTransform()
+}
+
+func (t Transform) Apply(p Point, pivot Origin) Point {
+	x := (p.X - pivot.X) * t.Scale.X
+	y := (p.Y - pivot.Y) * t.Scale.Y
+	z := (p.Z - pivot.Z) * t.Scale.Z
+
+	sin, cos := sinCos(t.Rotation.X)
+	y, z = y*cos-z*sin, y*sin+z*cos
+	sin, cos = sinCos(t.Rotation.Y)
+	x, z = x*cos+z*sin, -x*sin+z*cos
+	sin, cos = sinCos(t.Rotation.Z)
+	x, y = x*cos-y*sin, x*sin+y*cos
+
+	p.X = x + pivot.X + t.Translation.X
+	p.Y = y + pivot.Y + t.Translation.Y
+	p.Z = z + pivot.Z + t.Translation.Z
+
+	return p
+}
+
+// SetTransform replaces the transform of an entity and recalculates its points
+// from the geometry it was built with.
+func (w *World) SetTransform(k int, t Transform) {
+	entity := &w.Entities[k]
+	entity.Transform = t
+
+	pivot := entity.Pivot()
+	for p := range entity.base {
+		entity.Points[p] = t.Apply(entity.base[p], pivot)
+	}
+
+	w.UpdateEntity(k)
+}
+
+// Pivot returns the center of the untransformed geometry of the entity.
+func (e *Entity) Pivot() Origin {
+	base := Entity{Points: e.base}
+	base.UpdateBounds()
+
+	return base.Box.Center()
+}
+
+func sinCos(deg float32) (float32, float32) {
+	rad := float64(deg) * math.Pi / 180
+	return float32(math.Sin(rad)), float32(math.Cos(rad))
+}
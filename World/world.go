@@ -2,17 +2,46 @@ package World
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"log"
-	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
 )
 
 type World struct {
-	Entities []Entity
+	Entities   []Entity
+	Lights     []Light
+	files      []string
+	index      *BVH
+	indexDirty bool
+
+	// MaterialLibraries are the mtllib files of "obj" objects.
+	MaterialLibraries []string
 }
 
 type Entity struct {
-	Points []Point
-	Lines  []Line
+	Name      string
+	Tags      []string
+	Visible   bool
+	Type      string
+	Source    string
+	Points    []Point
+	Lines     []Line
+	Faces     []Face
+	Material  string
+	Color     *Color
+	Box       AABB
+	Sphere    BoundingSphere
+	Transform Transform
+	Object    FileObject
+	// Libraries are the mtllib files of an "obj" object, searched for its
+	// material before the material library.
+	Libraries []string
+
+	base      []Point
+	faceIndex *BVH
+	normals   []Origin
 }
 
 type Point struct {
@@ -27,6 +56,33 @@ type Line struct {
 	P2 int
 }
 
+// Face is a triangle of point indices wound counter-clockwise when seen from
+// outside of the entity.
+type Face struct {
+	P1 int
+	P2 int
+	P3 int
+}
+
+// AABB is an axis-aligned bounding box given by its minimum and maximum
+// corner.
+type AABB struct {
+	Min Origin
+	Max Origin
+}
+
+type BoundingSphere struct {
+	Center Origin
+	Radius float32
+}
+
+// Color holds RGB components in the 0-1 range.
+type Color struct {
+	R float32
+	G float32
+	B float32
+}
+
 type Square struct {
 	Origin Origin
 	Height float32
@@ -45,8 +101,15 @@ type FileData struct {
 }
 
 type FileObject struct {
-	Type string
-	Data string
+	Type      string
+	Name      string     `json:",omitempty"`
+	Tags      []string   `json:",omitempty"`
+	Hidden    bool       `json:",omitempty"`
+	Transform *Transform `json:",omitempty"`
+	Material  string     `json:",omitempty"`
+	Color     *Color     `json:",omitempty"`
+	Data      string
+	dir       string
 }
 
 func NewWorld() *World {
@@ -58,40 +121,83 @@ func NewWorld() *World {
 
 func (w *World) Build(worldDescriptor string) error {
 	log.Println("Building new world based on", worldDescriptor)
-	worldFile, err := os.Open(worldDescriptor)
+
+	err := w.buildFile(worldDescriptor, "", []string{})
 	if err != nil {
-		log.Println("Error opening world descriptor:", err.Error())
 		return err
 	}
 
-	buffer := make([]byte, 131072)
+	log.Println("World building complete")
 
-	n, err := worldFile.Read(buffer)
+	return nil
+}
 
+func (w *World) buildFile(worldDescriptor, namespace string, chain []string) error {
+	chain, err := enterInclude(worldDescriptor, chain)
 	if err != nil {
-		log.Println("Error reading world descriptor:", err.Error())
+		log.Println("Error including world descriptor:", err.Error())
 		return err
 	}
 
+	w.files = append(w.files, worldDescriptor)
+	buffer, err := ioutil.ReadFile(worldDescriptor)
+	if err != nil {
+		log.Println("Error reading world descriptor:", err.Error())
+		return errors.Wrap(err, worldDescriptor)
+	}
+
 	fileData := FileData{}
-	err = json.Unmarshal(buffer[:n], &fileData)
+	err = json.Unmarshal(buffer, &fileData)
 	if err != nil {
 		log.Println("Error parsing world descriptor:", err.Error())
-		return err
+		return errors.Wrap(err, worldDescriptor)
 	}
 
 	for _, v := range fileData.FileObjects {
+		if v.Type == "include" {
+			err = w.buildInclude(v, worldDescriptor, namespace, chain)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		first := len(w.Entities)
+		firstLight := len(w.Lights)
+		v.dir = filepath.Dir(worldDescriptor)
 		err = v.ParseObject(w)
 		if err != nil {
-			return err
+			return errors.Wrap(err, worldDescriptor)
 		}
-	}
 
-	log.Println("World building complete")
+		for k := first; k < len(w.Entities); k++ {
+			w.Entities[k].Name = JoinNamespace(namespace, v.Name)
+			w.Entities[k].Tags = append([]string{}, v.Tags...)
+			w.Entities[k].Visible = !v.Hidden
+			if v.Material != "" {
+				w.Entities[k].Material = v.Material
+			}
+			w.Entities[k].Color = v.Color
+			w.Entities[k].Object = v
+			if v.Transform != nil {
+				w.SetTransform(k, *v.Transform)
+			}
+			w.Entities[k].Source = worldDescriptor
+		}
+
+		for k := firstLight; k < len(w.Lights); k++ {
+			w.Lights[k].Name = JoinNamespace(namespace, v.Name)
+		}
+	}
 
 	return nil
 }
 
+// Files returns the descriptors and other files the world was built from.
+func (w *World) Files() []string {
+	return append([]string{}, w.files...)
+}
+
 func (f *FileObject) ParseObject(world *World) error {
 	switch f.Type {
 	case "square":
@@ -102,12 +208,23 @@ func (f *FileObject) ParseObject(world *World) error {
 			return err
 		}
 		world.BuildSquare(square)
+	case "terrain":
+		return f.parseTerrain(world)
+	case "sphere":
+		return f.parseSphere(world)
+	case "light":
+		return f.parseLight(world)
+	case "obj":
+		return f.parseModel(world)
 	}
 	return nil
 }
 
 func (w *World) BuildSquare(data Square) {
 	entity := Entity{}
+	entity.Type = "square"
+	entity.Visible = true
+	entity.Object = FileObject{Type: "square", Data: marshalData(data)}
 	entity.Points = []Point{}
 	entity.Lines = []Line{}
 
@@ -183,5 +300,40 @@ func (w *World) BuildSquare(data Square) {
 		}
 	}
 
-	w.Entities = append(w.Entities, entity)
+	entity.Faces = []Face{
+		{0, 1, 2}, {0, 2, 3},
+		{4, 6, 5}, {4, 7, 6},
+		{0, 4, 5}, {0, 5, 1},
+		{3, 2, 6}, {3, 6, 7},
+		{0, 3, 7}, {0, 7, 4},
+		{1, 5, 6}, {1, 6, 2},
+	}
+	entity.UpdateBounds()
+
+	w.AddEntity(entity)
+}
+
+// ConnectFaces rebuilds Lines and ConnectedTo from the edges of all faces.
+func (e *Entity) ConnectFaces() {
+	e.Lines = []Line{}
+	for k := range e.Points {
+		e.Points[k].ConnectedTo = []int{}
+	}
+
+	connected := map[[2]int]bool{}
+	for _, f := range e.Faces {
+		for _, edge := range [3][2]int{{f.P1, f.P2}, {f.P2, f.P3}, {f.P3, f.P1}} {
+			if edge[0] > edge[1] {
+				edge[0], edge[1] = edge[1], edge[0]
+			}
+			if connected[edge] {
+				continue
+			}
+			connected[edge] = true
+
+			e.Lines = append(e.Lines, Line{P1: edge[0], P2: edge[1]})
+			e.Points[edge[0]].ConnectedTo = append(e.Points[edge[0]].ConnectedTo, edge[1])
+			e.Points[edge[1]].ConnectedTo = append(e.Points[edge[1]].ConnectedTo, edge[0])
+		}
+	}
 }
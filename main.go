@@ -11,7 +11,10 @@ import (
 	"time"
 
 	"github.com/kanister10l/GoCamera/Camera"
+	"github.com/kanister10l/GoCamera/Editor"
+	"github.com/kanister10l/GoCamera/History"
 	"github.com/kanister10l/GoCamera/KeyCallbacks"
+	"github.com/kanister10l/GoCamera/Reload"
 	"github.com/kanister10l/GoCamera/World"
 
 	"github.com/go-gl/gl/v4.1-compatibility/gl" // OR: github.com/go-gl/gl/v2.1/gl
@@ -47,8 +50,140 @@ const (
 			frag_colour = vec4(colour, 1.0);
 		}
 	` + "\x00"
+
+	phongVertexShaderSource = `
+		#version 410
+		layout(location = 0) in vec3 vp;
+		layout(location = 1) in vec3 world_position;
+		layout(location = 2) in vec3 world_normal;
+
+		out vec3 position;
+		out vec3 normal;
+
+		void main() {
+			position = world_position;
+			normal = world_normal;
+			gl_Position = vec4(vp, 1.0);
+		}
+	` + "\x00"
+
+	// Same model as Camera.ShadeSurface evaluated per fragment
+	phongFragmentShaderSource = `
+		#version 410
+		#define MAX_LIGHTS 8
+		in vec3 position;
+		in vec3 normal;
+
+		uniform vec3 eye;
+		uniform int light_count;
+		uniform int light_type[MAX_LIGHTS];
+		uniform vec3 light_position[MAX_LIGHTS];
+		uniform vec3 light_direction[MAX_LIGHTS];
+		uniform vec3 light_color[MAX_LIGHTS];
+		uniform vec2 light_spot[MAX_LIGHTS];
+		uniform vec3 light_attenuation[MAX_LIGHTS];
+		uniform vec3 ambient;
+		uniform vec3 diffuse;
+		uniform vec3 specular;
+		uniform float shininess;
+		uniform int shading_model;
+		uniform float shading_param;
+		uniform int gamma_correct;
+
+		out vec4 frag_colour;
+
+		// diffuse and specular factor of Camera.ShadingModels, in their order
+		vec2 reflection(vec3 n, vec3 light, vec3 watch) {
+			float dif = max(dot(n, -light), 0.0);
+			if (shading_model == 1) {
+				vec3 h = normalize(watch - light);
+				return vec2(dif, pow(max(dot(n, h), 0.0), shininess));
+			}
+			if (shading_model == 2) {
+				return vec2(dif, 0.0);
+			}
+			if (shading_model == 3) {
+				float s2 = shading_param * shading_param;
+				float a = 1.0 - 0.5 * s2 / (s2 + 0.33);
+				float b = 0.45 * s2 / (s2 + 0.09);
+				float theta_i = acos(min(dif, 1.0));
+				float theta_r = acos(min(max(dot(n, watch), 0.0), 1.0));
+				vec3 light_tangent = -light - n * dot(-light, n);
+				vec3 watch_tangent = watch - n * dot(watch, n);
+				float cos_phi = 0.0;
+				if (length(light_tangent) > 0.0 && length(watch_tangent) > 0.0) {
+					cos_phi = max(0.0, dot(normalize(light_tangent), normalize(watch_tangent)));
+				}
+				return vec2(dif * (a + b * cos_phi * sin(max(theta_i, theta_r)) * tan(min(theta_i, theta_r))), 0.0);
+			}
+			if (shading_model == 4) {
+				float nv = max(dot(n, watch), 0.0);
+				vec3 h = normalize(watch - light);
+				float nh = max(dot(n, h), 0.0);
+				float vh = max(dot(watch, h), 0.0);
+				if (dif == 0.0 || nv == 0.0 || nh == 0.0 || vh == 0.0) {
+					return vec2(dif, 0.0);
+				}
+				float m2 = 2.0 / (shininess + 2.0);
+				float nh2 = nh * nh;
+				float d = exp((nh2 - 1.0) / (m2 * nh2)) / (3.14159265 * m2 * nh2 * nh2);
+				float f = shading_param + (1.0 - shading_param) * pow(1.0 - vh, 5.0);
+				float g = min(1.0, min(2.0 * nh * nv / vh, 2.0 * nh * dif / vh));
+				return vec2(dif, d * f * g / (4.0 * dif * nv));
+			}
+			return vec2(dif, pow(max(dot(reflect(light, n), watch), 0.0), shininess));
+		}
+
+		void main() {
+			vec3 n = normalize(normal);
+			vec3 watch = normalize(eye - position);
+			vec3 colour = ambient;
+			for (int i = 0; i < light_count; i++) {
+				vec3 light = light_direction[i];
+				float factor = 1.0;
+				if (light_type[i] != 1) {
+					light = position - light_position[i];
+					float d = length(light);
+					light = normalize(light);
+					vec3 a = light_attenuation[i];
+					float att = a.x + a.y * d + a.z * d * d;
+					if (att > 0.0) {
+						factor = 1.0 / att;
+					}
+				}
+				if (light_type[i] == 2) {
+					float angle = dot(light, light_direction[i]);
+					if (angle < light_spot[i].x) {
+						continue;
+					}
+					if (light_spot[i].y > 0.0) {
+						factor *= pow(angle, light_spot[i].y);
+					}
+				}
+				if (dot(n, watch) < 0.0 || dot(n, -light) < 0.0) {
+					continue;
+				}
+				vec2 r = reflection(n, light, watch);
+				colour += factor * light_color[i] * (diffuse * r.x + specular * r.y);
+			}
+			colour = min(colour, vec3(1.0));
+			if (gamma_correct == 1) {
+				colour = mix(colour * 12.92, 1.055 * pow(colour, vec3(1.0 / 2.4)) - 0.055, step(0.0031308, colour));
+			}
+			frag_colour = vec4(colour, 1.0);
+		}
+	` + "\x00"
 )
 
+// worldUpdate is a rebuilt world together with the materials of its "obj"
+// objects, both loaded by the watcher. When the build failed world is nil and
+// files are the ones it read.
+type worldUpdate struct {
+	world        *World.World
+	objMaterials map[string]Camera.Material
+	files        []string
+}
+
 func main() {
 	rand.Seed(time.Now().Unix())
 	runtime.LockOSThread()
@@ -56,8 +191,29 @@ func main() {
 	widthPtr := flag.Int("width", 1280, "Width of the window in pixels")
 	heightPtr := flag.Int("height", 720, "Height of the window in pixels")
 	spComp := flag.Int("spc", 0, "Sphere Level of detail Available: 0,1,2,3")
+	worldPath := flag.String("world", "worldDescriptor.json", "World descriptor to load")
+	savePath := flag.String("save", "worldDescriptor.edited.json", "World descriptor written by the editor")
+	historyLimit := flag.Int("history", 100, "Number of changes which can be undone")
+	materialPath := flag.String("materials", "./materials.json", "Material library to load, JSON or Wavefront MTL by extension")
+	materialSavePath := flag.String("materialsave", "", "Material library written by the material editor, the -materials file when empty")
+	shading := flag.String("shading", "flat", "Shading of world entities Available: flat, gouraud, phong")
+	sphereKind := flag.String("sphere", "uv", "Sphere mesh of sphere mode Available: "+strings.Join(Camera.SphereKinds, ", "))
+	ringsFlag := flag.Int("rings", 0, "Rings of the sphere, overrides -spc when positive")
+	segmentsFlag := flag.Int("segments", 0, "Segments of the sphere, overrides -spc when positive")
+	lod := flag.Bool("lod", false, "Pick the sphere resolution from its size on the screen")
+	toneMapping := flag.String("tonemap", "clamp", "Tone mapping of sphere mode Available: "+strings.Join(Camera.ToneMappingNames, ", "))
+	exposure := flag.Float64("exposure", 0, "Exposure of sphere mode in stops")
+	gamma := flag.Bool("gamma", true, "Light in linear space, treating material colors as sRGB")
+	sheetPath := flag.String("sheet", "", "Render every material on a sphere of fixed tessellation, lighting and tone into this PNG contact sheet, then quit")
+	sheetColumns := flag.Int("sheetcolumns", 6, "Columns of the contact sheet")
+	sheetCell := flag.Int("sheetcell", 160, "Size in pixels of a contact sheet cell")
+	workers := flag.Int("workers", runtime.NumCPU(), "Goroutines sharing sphere lighting and polygon generation")
+	model := flag.String("model", "phong", "Shading model of the sphere and world entities Available: "+strings.Join(Camera.ShadingModelNames(), ", "))
+	lightsPath := flag.String("lights", "", "Light sources of sphere mode, a single white light when empty")
+	watchInterval := flag.Duration("watch", time.Second, "Interval of checking loaded files for changes, 0 disables reloading")
 
 	flag.Parse()
+	Camera.Workers = *workers
 
 	width := *widthPtr
 	height := *heightPtr
@@ -77,21 +233,92 @@ func main() {
 	}
 
 	camera := Camera.NewCameraAt(0.0, 0.0, 0.0, 75, float32(width)/float32(height))
-	materials := Camera.LoadMaterial("./materials.json")
+	camera.GammaCorrect = *gamma
+	// the built-in library must not replace a library which failed to load
+	materialSave := *materialSavePath
+	materials, err := Camera.LoadMaterialLibrary(*materialPath)
+	if err != nil {
+		log.Println("Error loading materials, using the built-in library:", err)
+		materials = Camera.DefaultMaterials()
+	} else if materialSave == "" {
+		materialSave = *materialPath
+	}
 	sphereWorld := Camera.CreateSphereWorld(0, 0, 20, 30, materials)
+	if *lightsPath != "" {
+		lights, err := Camera.LoadSphereLights(*lightsPath, sphereWorld.Radius, sphereWorld.Origin())
+		if err != nil {
+			log.Println("Error loading lights:", err)
+			os.Exit(127)
+		}
+		sphereWorld.Lights = lights
+	}
+	if k, ok := Camera.FindToneMapping(*toneMapping); ok {
+		sphereWorld.Tone.Operator = k
+	} else {
+		log.Println("Unknown tone mapping:", *toneMapping)
+	}
+	sphereWorld.Tone.Exposure = float32(*exposure)
+	if k, ok := Camera.FindShadingModel(*model); ok {
+		sphereWorld.Model = k
+	} else {
+		log.Println("Unknown shading model:", *model)
+	}
 
-	spherePoints := Camera.GenerateSphere(10, 0, 0, 20, vRes, aRes)
+	if !Camera.IsSphereKind(*sphereKind) {
+		log.Println("Unknown sphere kind:", *sphereKind)
+		*sphereKind = "uv"
+	}
+	rings := vRes / 2
+	if *sphereKind == "disc" {
+		rings = vRes
+	}
+	if *ringsFlag > 0 {
+		rings = *ringsFlag
+	}
+	segments := aRes
+	if *segmentsFlag > 0 {
+		segments = *segmentsFlag
+	}
+	sphereTessellation := Camera.NewSphereTessellation(*sphereKind, World.Origin{X: 0, Y: 0, Z: 20}, 10, rings, segments, *spComp+2)
+	sphereTessellation.Auto = *lod
+
+	if *sheetPath != "" {
+		err := Camera.SaveContactSheet(*sheetPath, sphereWorld, *sheetColumns, *sheetCell)
+		if err != nil {
+			log.Println("Error rendering contact sheet:", err)
+			os.Exit(127)
+		}
+		log.Println("Contact sheet saved to", *sheetPath)
+		return
+	}
 
 	world := World.NewWorld()
-	err := world.Build("worldDescriptor.json")
+	err = world.Build(*worldPath)
 	if err != nil {
+		log.Println("Error building world:", err)
 		os.Exit(127)
 	}
+	objMaterials := Camera.LoadWorldMaterials(world)
+	logShadowedMaterials(Camera.WorldMaterials{Library: sphereWorld.Materials, Libraries: objMaterials})
 
 	window := initGlfw(width, height)
 	defer glfw.Terminate()
 	program := initOpenGL()
-	KeyCallbacks.SetCallbacks(window, camera, world, sphereWorld)
+	phongProgram, err := linkProgram(phongVertexShaderSource, phongFragmentShaderSource)
+	if err != nil {
+		log.Println("Error building the Phong shader, phong shading falls back to gouraud:", err)
+	} else {
+		camera.PhongProgram = phongProgram
+	}
+	if k, ok := Camera.FindShading(*shading); ok {
+		camera.ShadingMode = k
+	} else {
+		log.Println("Unknown shading:", *shading)
+	}
+	history := History.NewHistory(*historyLimit)
+	editor := Editor.NewEditor(camera, world, history, *savePath)
+	materialEditor := Editor.NewMaterialEditor(sphereWorld, history, materialSave)
+	KeyCallbacks.SetCallbacks(window, camera, world, sphereWorld, sphereTessellation, editor, materialEditor, history)
 
 	log.Println(`
 	KeyBindings:
@@ -110,38 +337,135 @@ func main() {
 	R ---> Reset Camera to Original Position
 	PGDN ---> Change painting type
 	PGUP ---> Change to sphere mode
-	KeyPad [2, 4, 6, 8] ---> Rotate light source around sphere
+	KeyPad [2, 4, 6, 8] ---> Rotate selected light source around sphere
+	L ---> Select next light source
+	K ---> Add light source
+	O ---> Remove selected light source
+	I ---> Enable or disable selected light source
+	T ---> Cycle point, directional and spot type of selected light source
+	KeyPad [-, +] ---> Adjust selected light intensity
 	[1, 2] ---> [-, +] Adjust Hue
 	[3, 4] ---> [-, +] Adjust Ambient reflection
 	[5, 6] ---> [-, +] Adjust Diffuse reflection
 	[7, 8] ---> [-, +] Adjust Specular reflection
 	[9, 0] ---> [-, +] Adjust Shininess
+	V ---> Cycle shading model
+	KeyPad [/, *] ---> [-, +] Adjust sphere tessellation
+	KeyPad 5 ---> Cycle clamp, reinhard and aces tone mapping
+	KeyPad [7, 9] ---> [-, +] Adjust exposure
+	KeyPad ENTER ---> Toggle automatic sphere level of detail
+	C ---> Compare sphere shading models side by side
+	G ---> Cycle flat, gouraud and phong shading
+	F ---> Toggle gamma correct lighting
+	B ---> Add camera bookmark
+	N ---> Go to next camera bookmark
+	Z ---> Undo
+	X ---> Redo
+	P ---> Log culled and drawn entity counts
+	Left Mouse Button ---> Log entity under cursor
+	E ---> Toggle edit mode
+	Edit mode:
+	Left Mouse Button ---> Select entity
+	TAB ---> Cycle move, rotate and scale tool
+	KeyPad [4, 6] [8, 2] [7, 9] ---> Apply tool along X, Y and Z
+	INSERT ---> Add box at camera target
+	DELETE ---> Delete selected entity
+	F2 ---> Save world
+	Q ---> Toggle material edit mode in sphere mode
+	Material edit mode:
+	TAB ---> Cycle ambient, diffuse and specular property
+	[1, 2] [3, 4] [5, 6] ---> [-, +] Adjust red, green and blue of property
+	[7, 8] ---> [-, +] Adjust all channels of property
+	[9, 0] ---> [-, +] Adjust shininess
+	INSERT ---> Copy selected material
+	F2 ---> Save materials
 	ESC ---> Quit`)
 
+	worldUpdates := make(chan worldUpdate, 1)
+	materialUpdates := make(chan Camera.Material, 1)
+	var worldWatcher *Reload.Watcher
+	if *watchInterval > 0 {
+		worldWatcher = Reload.Watch(world.Files(), *watchInterval, func() {
+			newWorld := World.NewWorld()
+			err := newWorld.Build(*worldPath)
+			if err != nil {
+				log.Println("Keeping previous world:", err)
+				worldUpdates <- worldUpdate{files: newWorld.Files()}
+				return
+			}
+			worldUpdates <- worldUpdate{world: newWorld, objMaterials: Camera.LoadWorldMaterials(newWorld)}
+		})
+		Reload.Watch([]string{*materialPath}, *watchInterval, func() {
+			newMaterials, err := Camera.LoadMaterialLibrary(*materialPath)
+			if err != nil {
+				log.Println("Keeping previous materials:", err)
+				return
+			}
+			materialUpdates <- newMaterials
+		})
+	}
+
 	for !window.ShouldClose() {
-		draw(window, program, camera, world, spherePoints, float32(width)/float32(height), sphereWorld)
+		select {
+		case update := <-worldUpdates:
+			if update.world == nil {
+				// also watch what the failed build tried to read, so that
+				// creating a missing file retries it
+				worldWatcher.SetFiles(append(world.Files(), update.files...))
+				break
+			}
+			*world = *update.world
+			objMaterials = update.objMaterials
+			worldWatcher.SetFiles(world.Files())
+			logShadowedMaterials(Camera.WorldMaterials{Library: sphereWorld.Materials, Libraries: objMaterials})
+			history.ClearScope(History.ScopeWorld)
+			editor.Selected = -1
+			log.Println("World reloaded")
+		case newMaterials := <-materialUpdates:
+			sphereWorld.SetMaterials(newMaterials)
+			history.ClearScope(History.ScopeMaterials)
+			logShadowedMaterials(Camera.WorldMaterials{Library: sphereWorld.Materials, Libraries: objMaterials})
+			if *materialSavePath == "" {
+				materialEditor.SavePath = *materialPath
+			}
+			log.Println("Materials reloaded")
+		default:
+		}
+
+		draw(window, program, camera, world, objMaterials, sphereTessellation, sphereWorld)
 	}
 }
 
-func draw(window *glfw.Window, program uint32, camera *Camera.Camera, world *World.World, spherePoints []Camera.SpherePoint, ratio float32, sphereWorld *Camera.SphereWorld) {
+func draw(window *glfw.Window, program uint32, camera *Camera.Camera, world *World.World, objMaterials map[string]Camera.Material, sphereTessellation *Camera.SphereTessellation, sphereWorld *Camera.SphereWorld) {
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 	gl.UseProgram(program)
 
+	// the material editor may replace the library, so it is looked up every
+	// frame without copying it
+	materials := Camera.WorldMaterials{Library: sphereWorld.Materials, Libraries: objMaterials}
+
 	if camera.DrawType == 0 {
 		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-		camera.DrawWorld(world)
+		camera.DrawWorld(world, materials)
 	} else if camera.DrawType == 1 {
 		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-		camera.DrawFullWorld(world)
+		camera.DrawFullWorld(world, materials, Camera.ShadingModels[sphereWorld.Model])
 	} else if camera.DrawType == 2 {
 		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-		camera.DrawSphere(spherePoints, ratio, sphereWorld)
+		sphereTessellation.UpdateLevelOfDetail(camera)
+		camera.DrawSphere(sphereTessellation.Mesh(), sphereWorld)
 	}
 
 	glfw.PollEvents()
 	window.SwapBuffers()
 }
 
+func logShadowedMaterials(materials Camera.WorldMaterials) {
+	for _, name := range materials.Shadowed() {
+		log.Println("Obj material shadows the library material:", name)
+	}
+}
+
 func initGlfw(width, height int) *glfw.Window {
 	if err := glfw.Init(); err != nil {
 		panic(err)
@@ -168,21 +492,45 @@ func initOpenGL() uint32 {
 	version := gl.GoStr(gl.GetString(gl.VERSION))
 	log.Println("OpenGL version", version)
 
-	vertexShader, err := compileShader(vertexShaderSource, gl.VERTEX_SHADER)
+	prog, err := linkProgram(vertexShaderSource, fragmentShaderSource)
 	if err != nil {
 		panic(err)
 	}
+	return prog
+}
 
-	fragmentShader, err := compileShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
+func linkProgram(vertexSource, fragmentSource string) (uint32, error) {
+	vertexShader, err := compileShader(vertexSource, gl.VERTEX_SHADER)
 	if err != nil {
-		panic(err)
+		return 0, err
+	}
+	defer gl.DeleteShader(vertexShader)
+
+	fragmentShader, err := compileShader(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return 0, err
 	}
+	defer gl.DeleteShader(fragmentShader)
 
 	prog := gl.CreateProgram()
 	gl.AttachShader(prog, vertexShader)
 	gl.AttachShader(prog, fragmentShader)
 	gl.LinkProgram(prog)
-	return prog
+
+	var status int32
+	gl.GetProgramiv(prog, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(prog, gl.INFO_LOG_LENGTH, &logLength)
+
+		logProgram := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(prog, logLength, nil, gl.Str(logProgram))
+		gl.DeleteProgram(prog)
+
+		return 0, fmt.Errorf("failed to link program: %v", logProgram)
+	}
+
+	return prog, nil
 }
 
 func compileShader(source string, shaderType uint32) (uint32, error) {